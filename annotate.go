@@ -0,0 +1,192 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "tui-chess/chess"
+)
+
+// the glyphs offered in the glyph picker, any other glyph can be typed as a number
+var nagOptions = []int{1, 2, 3, 4, 5, 6, 10, 14, 15, 16, 17, 18, 19}
+
+// opens the comment editor for the last move, before the first move the comment is on the game
+func (m *model) editComment() {
+    m.editingComment = true
+    m.input = m.game.Current().Comment
+}
+
+// handles key presses in the comment editor
+func (m model) updateCommentEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    switch msg.Type {
+
+    /* quit program, letters are part of the comment */
+    case tea.KeyCtrlC, tea.KeyCtrlD:
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* close the editor without changing the comment */
+    case tea.KeyEsc:
+        m.editingComment = false
+        m.input = ""
+
+    /* save the comment, an empty comment removes it */
+    case tea.KeyEnter:
+        m.game.Current().Comment = strings.Join(strings.Fields(m.input), " ")
+        m.editingComment = false
+        m.input = ""
+        logToFile("comment on " + m.annotatedMove() + ": " + m.game.Current().Comment)
+
+    /* delete the last letter */
+    case tea.KeyBackspace:
+        m.input = dropLastRune(m.input)
+
+    /* type, PGN comments end at the first } so it can not be part of them */
+    case tea.KeyRunes, tea.KeySpace:
+        m.input += strings.ReplaceAll(string(msg.Runes), "}", "")
+    }
+
+    return m, nil
+}
+
+// opens the glyph picker for the last move
+func (m *model) chooseNAG() {
+    if m.game.Current() == m.game.Root() {
+        m.message = "play a move before annotating it"
+        return
+    }
+
+    m.choosingNAG = true
+    m.input = ""
+}
+
+// handles key presses in the glyph picker
+func (m model) updateNAGPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    switch msg.String() {
+
+    /* quit program */
+    case "ctrl+c", "ctrl+d":
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* close the picker */
+    case "esc", "a":
+        m.choosingNAG = false
+        m.input = ""
+
+    /* move choice left */
+    case "h", "left":
+        if m.nagChoice != 0 {
+            m.nagChoice --
+        }
+
+    /* move choice right */
+    case "l", "right":
+        if m.nagChoice < len(nagOptions) - 1 {
+            m.nagChoice ++
+        }
+
+    /* add or remove the chosen glyph, or the glyph with the typed number */
+    case "enter", " ":
+        nag := nagOptions[m.nagChoice]
+        if m.input != "" {
+            var err error
+            nag, err = strconv.Atoi(m.input)
+            m.input = ""
+
+            // $0 is the null annotation, which is not a glyph
+            if err != nil || nag < 1 || nag > 255 {
+                m.message = "glyphs are numbered from 1 to 255"
+                return m, nil
+            }
+        }
+        m.game.Current().ToggleNAG(nag)
+        logToFile("annotated " + m.annotatedMove())
+
+    /* delete the last digit */
+    case "backspace":
+        m.input = dropLastRune(m.input)
+
+    /* type the number of a glyph */
+    case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+        if len(m.input) < 3 {
+            m.input += msg.String()
+        }
+    }
+
+    return m, nil
+}
+
+// the last move with its move number and glyphs, like 12... Nf3!?
+func (m model) annotatedMove() string {
+    position := m.game.Position()
+
+    // the move number of the last move, which is one lower if white moves next
+    number := position.FullmoveNumber()
+    dots := "..."
+    if position.Turn() == chess.PieceColorBlack {
+        dots = "."
+    } else {
+        number --
+    }
+
+    return strconv.Itoa(number) + dots + " " + m.game.Current().Annotated()
+}
+
+// draws the comment editor shown below the board
+func (m model) commentEditorView() string {
+    title := "the game"
+    if m.game.Current() != m.game.Root() {
+        title = m.annotatedMove()
+    }
+
+    s := "\n"
+    s += boardColor + "|------------------ comment ---------------------|\n" + Reset
+    s += "  on " + title + "\n"
+    s += "  " + m.input + "_\n"
+    s += "  enter to save and esc to cancel, an empty comment is removed\n"
+    s += boardColor + "|------------------------------------------------|\n" + Reset
+
+    return s
+}
+
+// draws the glyph picker shown below the board, the glyphs of the move are marked
+func (m model) nagPickerView() string {
+    s := "\n"
+    s += boardColor + "|------------------ annotate --------------------|\n" + Reset
+    s += "  " + m.annotatedMove() + "\n"
+    s += "  "
+
+    for i, nag := range nagOptions {
+        color := boardColor
+        if m.hasNAG(nag) {
+            color = possibleMoveColor
+        }
+        if i == m.nagChoice {
+            color = selectedColor
+        }
+        s += color + "[" + chess.NAGSymbol(nag) + "]" + Reset + " "
+    }
+
+    s += "\n"
+    if m.input != "" {
+        s += "  $" + m.input + "_\n"
+    }
+    s += "  h/l to choose and enter to add or remove, or type the number of a glyph from 1 to 255\n"
+    s += boardColor + "|------------------------------------------------|\n" + Reset
+
+    return s
+}
+
+// checks if the last move has the glyph
+func (m model) hasNAG(nag int) bool {
+    for _, other := range m.game.Current().NAGs {
+        if other == nag {
+            return true
+        }
+    }
+    return false
+}
@@ -1,92 +1,42 @@
 package main
 
-var boardDefault = [8][8]piece{
-   {rookBlack, knightBlack, bishopBlack, queenBlack, kingBlack, knightBlack, bishopBlack, rookBlack},
-   {pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite},
-   {rookWhite, knightWhite, bishopWhite, queenWhite, kingWhite, bishopWhite, knightWhite, rookWhite},
+import (
+    "errors"
+
+    "tui-chess/chess"
+)
+
+// the built in boards in Forsyth-Edwards Notation
+// the standard perft positions are from https://www.chessprogramming.org/Perft_Results
+var boards = map[string]string{
+    "default": chess.StartingFEN,
+
+    "testPawn": "8/pppppppp/8/2p5/3P4/8/PPPPPPPP/8 w - - 0 1",
+    "testRook": "2P2P2/8/5P2/8/1r2P3/8/1P2r3/8 w - - 0 1",
+    "testBishop": "8/1B6/8/1P1b4/1P6/1P6/1P6/1P6 w - - 0 1",
+    "testKnight": "8/1n6/8/1P1np3/1P3p2/1P3p2/1P3p2/1P3p2 w - - 0 1",
+    "testQueen": "8/8/8/3q4/8/8/8/8 w - - 0 1",
+    "testKing": "8/8/8/3K4/8/8/3Pp3/3K4 w - - 0 1",
+    "testEmpty": "8/8/8/8/8/8/8/8 w - - 0 1",
+
+    "perftKiwipete": "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+    "perftPosition3": "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+    "perftPosition4": "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+    "perftPosition5": "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+    "perftPosition6": "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+}
+
+// returns the position of the board with the given name
+func getBoard(name string) (error, chess.Position) {
+    fen, ok := boards[name]
+    if !ok {
+        return errors.New("unrecognized board " + name), chess.Position{}
+    }
+
+    err, p := chess.ParseFEN(fen)
+    if err != nil {
+        return errors.New("board " + name + ": " + err.Error()), chess.Position{}
+    }
+
+    return nil, p
 }
-
-var boardTestPawn = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack, pawnBlack},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, pawnBlack, empty, empty, empty, empty, empty},
-   {empty, empty, empty, pawnWhite, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite, pawnWhite},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-}
-
-var boardTestRook = [8][8]piece{
-   {empty, empty, pawnWhite, empty, empty, pawnWhite, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, pawnWhite, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, rookBlack, empty, empty, pawnWhite, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, rookBlack, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-}
-
-var boardTestBishop = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, bishopWhite, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, bishopBlack, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, empty, empty, empty},
-}
-
-var boardTestKnight = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, knightBlack, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, pawnWhite, empty, knightBlack, pawnBlack, empty, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, pawnBlack, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, pawnBlack, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, pawnBlack, empty, empty},
-   {empty, pawnWhite, empty, empty, empty, pawnBlack, empty, empty},
-}
-
-var boardTestQueen = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, queenBlack, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-}
-
-var boardTestKing = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, kingWhite, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, pawnWhite, pawnBlack, empty, empty, empty},
-   {empty, empty, empty, kingWhite, empty, empty, empty, empty},
-}
-
-
-var boardTestEmpty = [8][8]piece{
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-   {empty, empty, empty, empty, empty, empty, empty, empty},
-}
-
-
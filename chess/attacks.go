@@ -0,0 +1,104 @@
+package chess
+
+// recomputes which squares each color attacks and by how many pieces
+// sliding pieces attack through the opponent king, so the squares behind a checked king count as attacked
+// and the king can not escape a check by stepping back along the line of the attack
+func (p *Position) updateAttacks() {
+    for color := PieceColorWhite; color <= PieceColorBlack; color++ {
+        occupied := (p.occupied[PieceColorWhite] | p.occupied[PieceColorBlack]) &^ p.pieces[color.Opponent()][PieceKindKing]
+
+        p.attacks[color] = 0
+        p.attackCounts[color] = [64]uint8{}
+
+        for kind := PieceKindPawn; kind <= PieceKindKing; kind++ {
+            pieces := p.pieces[color][kind]
+
+            for pieces != 0 {
+                attacks := pieceAttacks(Piece{Kind: kind, Color: color}, popSquare(&pieces), occupied)
+                p.attacks[color] |= attacks
+
+                for attacks != 0 {
+                    p.attackCounts[color][popSquare(&attacks)] ++
+                }
+            }
+        }
+    }
+}
+
+// the squares attacked by pieces of the color, sliding pieces attack through the opponent king
+func (p *Position) Attacks(color PieceColor) []int {
+    squares := []int{}
+
+    for attacks := p.attacks[color]; attacks != 0; {
+        squares = append(squares, popSquare(&attacks))
+    }
+
+    return squares
+}
+
+// the number of pieces of the color attacking the square
+func (p *Position) AttackCount(sq int, color PieceColor) int {
+    return int(p.attackCounts[color][sq])
+}
+
+// the squares a piece on sq attacks
+func pieceAttacks(pc Piece, sq int, occupied bitboard) bitboard {
+    switch pc.Kind {
+        case PieceKindPawn:
+            return pawnAttacks[pc.Color][sq]
+        case PieceKindKnight:
+            return knightAttacks[sq]
+        case PieceKindBishop:
+            return bishopAttacks(sq, occupied)
+        case PieceKindRook:
+            return rookAttacks(sq, occupied)
+        case PieceKindQueen:
+            return bishopAttacks(sq, occupied) | rookAttacks(sq, occupied)
+        case PieceKindKing:
+            return kingAttacks[sq]
+    }
+    return 0
+}
+
+// checks if any king of the color is attacked, positions without a king are never in check
+func (p *Position) InCheck(color PieceColor) bool {
+    return p.attacks[color.Opponent()] & p.pieces[color][PieceKindKing] != 0
+}
+
+// checks if any king of the color is attacked without using the attack maps, for boards changed by movePieces
+func (p *Position) kingAttacked(color PieceColor) bool {
+    kings := p.pieces[color][PieceKindKing]
+
+    for kings != 0 {
+        if p.isAttacked(popSquare(&kings), color.Opponent()) {
+            return true
+        }
+    }
+
+    return false
+}
+
+// checks if the square is attacked by any piece of the color attacker
+func (p *Position) isAttacked(sq int, attacker PieceColor) bool {
+    pieces := &p.pieces[attacker]
+    occupied := p.occupied[PieceColorWhite] | p.occupied[PieceColorBlack]
+
+    // a pawn attacks sq if a pawn of the other color on sq would attack the pawn
+    if pawnAttacks[attacker.Opponent()][sq] & pieces[PieceKindPawn] != 0 {
+        return true
+    }
+    if knightAttacks[sq] & pieces[PieceKindKnight] != 0 {
+        return true
+    }
+    if kingAttacks[sq] & pieces[PieceKindKing] != 0 {
+        return true
+    }
+    if bishopAttacks(sq, occupied) & (pieces[PieceKindBishop] | pieces[PieceKindQueen]) != 0 {
+        return true
+    }
+    if rookAttacks(sq, occupied) & (pieces[PieceKindRook] | pieces[PieceKindQueen]) != 0 {
+        return true
+    }
+
+    return false
+}
@@ -0,0 +1,149 @@
+package chess
+
+import "math/bits"
+
+const rowsAndColums = 8
+
+// the column and row of a square, row 0 is the top of the board
+type coordinate struct {
+    x int
+    y int
+}
+
+// a bitboard has one bit for each square of the board
+// square 0 is the top left corner (a8) and square 63 is the bottom right corner (h1)
+type bitboard uint64
+
+/* directions sliding pieces move in, the first four are used by rooks and the last four by bishops */
+const (
+    directionRight = iota
+    directionLeft
+    directionDown
+    directionUp
+    directionRightDown
+    directionRightUp
+    directionLeftDown
+    directionLeftUp
+)
+
+var directionSteps = [8]coordinate{
+    {1, 0}, {-1, 0}, {0, 1}, {0, -1},
+    {1, 1}, {1, -1}, {-1, 1}, {-1, -1},
+}
+
+var rookDirections = []int{directionRight, directionLeft, directionDown, directionUp}
+var bishopDirections = []int{directionRightDown, directionRightUp, directionLeftDown, directionLeftUp}
+
+/* precomputed attack tables */
+
+// the squares a knight on each square attacks
+var knightAttacks [64]bitboard
+// the squares a king on each square attacks
+var kingAttacks [64]bitboard
+// the squares a pawn of each color on each square attacks
+var pawnAttacks [2][64]bitboard
+// every square from each square to the edge of the board in each direction, not including the square itself
+var rays [8][64]bitboard
+
+// squares where x + y is even, a8 is a light square
+var lightSquares bitboard
+
+func init() {
+    knightSteps := []coordinate{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}, {2, 1}, {2, -1}, {-2, 1}, {-2, -1}}
+
+    for sq := 0; sq < 64; sq++ {
+        c := coordinateOf(sq)
+
+        if (c.x + c.y) % 2 == 0 {
+            lightSquares |= squareBit(sq)
+        }
+
+        for _, step := range knightSteps {
+            knightAttacks[sq] |= coordinateBit(coordinate{c.x + step.x, c.y + step.y})
+        }
+
+        for _, step := range directionSteps {
+            kingAttacks[sq] |= coordinateBit(coordinate{c.x + step.x, c.y + step.y})
+        }
+
+        // white pawns move up the board and black pawns move down
+        pawnAttacks[PieceColorWhite][sq] = coordinateBit(coordinate{c.x - 1, c.y - 1}) | coordinateBit(coordinate{c.x + 1, c.y - 1})
+        pawnAttacks[PieceColorBlack][sq] = coordinateBit(coordinate{c.x - 1, c.y + 1}) | coordinateBit(coordinate{c.x + 1, c.y + 1})
+
+        for direction, step := range directionSteps {
+            for i := 1; checkIfOnBoard(coordinate{c.x + step.x * i, c.y + step.y * i}); i++ {
+                rays[direction][sq] |= coordinateBit(coordinate{c.x + step.x * i, c.y + step.y * i})
+            }
+        }
+    }
+}
+
+// the squares a rook on sq attacks, the attack stops at the first occupied square in each direction
+func rookAttacks(sq int, occupied bitboard) bitboard {
+    return slidingAttacks(sq, occupied, rookDirections)
+}
+
+// the squares a bishop on sq attacks, the attack stops at the first occupied square in each direction
+func bishopAttacks(sq int, occupied bitboard) bitboard {
+    return slidingAttacks(sq, occupied, bishopDirections)
+}
+
+func slidingAttacks(sq int, occupied bitboard, directions []int) bitboard {
+    var attacks bitboard
+
+    for _, direction := range directions {
+        ray := rays[direction][sq]
+        blockers := ray & occupied
+
+        // remove the squares behind the first blocker, which is the closest square to sq on the ray
+        if blockers != 0 {
+            step := directionSteps[direction]
+            blocker := 0
+            if step.y > 0 || step.y == 0 && step.x > 0 {
+                blocker = bits.TrailingZeros64(uint64(blockers))
+            } else {
+                blocker = 63 - bits.LeadingZeros64(uint64(blockers))
+            }
+            ray &^= rays[direction][blocker]
+        }
+
+        attacks |= ray
+    }
+
+    return attacks
+}
+
+func squareBit(sq int) bitboard {
+    return 1 << uint(sq)
+}
+
+// the bit of the square at c, or an empty bitboard if c is outside the board
+func coordinateBit(c coordinate) bitboard {
+    if !checkIfOnBoard(c) {
+        return 0
+    }
+    return squareBit(squareOf(c))
+}
+
+// removes the lowest set square from the bitboard and returns it
+func popSquare(b *bitboard) int {
+    sq := bits.TrailingZeros64(uint64(*b))
+    *b &= *b - 1
+    return sq
+}
+
+func (b bitboard) count() int {
+    return bits.OnesCount64(uint64(b))
+}
+
+func squareOf(c coordinate) int {
+    return c.y * rowsAndColums + c.x
+}
+
+func coordinateOf(sq int) coordinate {
+    return coordinate{sq % rowsAndColums, sq / rowsAndColums}
+}
+
+func checkIfOnBoard(c coordinate) bool {
+    return c.x >= 0 && c.x < rowsAndColums && c.y >= 0 && c.y < rowsAndColums
+}
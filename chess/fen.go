@@ -0,0 +1,259 @@
+package chess
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+)
+
+// the standard starting position
+const StartingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// writes the position in Forsyth-Edwards Notation
+func (p *Position) FEN() string {
+    var b strings.Builder
+
+    for y := 0; y < rowsAndColums; y++ {
+        emptySquares := 0
+
+        for x := 0; x < rowsAndColums; x++ {
+            pc := p.squares[squareOf(coordinate{x, y})]
+            if pc.Kind == PieceKindNone {
+                emptySquares ++
+                continue
+            }
+
+            if emptySquares != 0 {
+                b.WriteString(strconv.Itoa(emptySquares))
+                emptySquares = 0
+            }
+            b.WriteByte(fenLetter(pc))
+        }
+
+        if emptySquares != 0 {
+            b.WriteString(strconv.Itoa(emptySquares))
+        }
+        if y != rowsAndColums - 1 {
+            b.WriteByte('/')
+        }
+    }
+
+    if p.turn == PieceColorWhite {
+        b.WriteString(" w ")
+    } else {
+        b.WriteString(" b ")
+    }
+
+    castling := ""
+    if p.castlingRights.whiteKingSide {
+        castling += "K"
+    }
+    if p.castlingRights.whiteQueenSide {
+        castling += "Q"
+    }
+    if p.castlingRights.blackKingSide {
+        castling += "k"
+    }
+    if p.castlingRights.blackQueenSide {
+        castling += "q"
+    }
+    if castling == "" {
+        castling = "-"
+    }
+    b.WriteString(castling)
+
+    if p.enPassant == -1 {
+        b.WriteString(" -")
+    } else {
+        b.WriteString(" " + SquareName(p.enPassant))
+    }
+
+    b.WriteString(" " + strconv.Itoa(p.halfmoveClock) + " " + strconv.Itoa(p.fullmoveNumber))
+
+    return b.String()
+}
+
+// reads a position written in Forsyth-Edwards Notation
+// the halfmove clock and fullmove number can be left out, they default to 0 and 1
+func ParseFEN(fen string) (error, Position) {
+    fields := strings.Fields(fen)
+    if len(fields) != 4 && len(fields) != 6 {
+        return errors.New("FEN must have 4 or 6 fields separated by spaces, found " + strconv.Itoa(len(fields))), Position{}
+    }
+
+    var board [8][8]Piece
+    for y := range board {
+        for x := range board[y] {
+            board[y][x] = NoPiece
+        }
+    }
+
+    rows := strings.Split(fields[0], "/")
+    if len(rows) != rowsAndColums {
+        return errors.New("FEN board must have 8 ranks separated by /, found " + strconv.Itoa(len(rows))), Position{}
+    }
+
+    for y, row := range rows {
+        rank := strconv.Itoa(rowsAndColums - y)
+        x := 0
+
+        for i := 0; i < len(row); i++ {
+            c := row[i]
+
+            if c >= '1' && c <= '8' {
+                x += int(c - '0')
+            } else {
+                kind, ok := pieceKindOf(c)
+                if !ok {
+                    return errors.New("unknown piece " + string(c) + " on rank " + rank), Position{}
+                }
+                if x >= rowsAndColums {
+                    return errors.New("rank " + rank + " has more than 8 squares"), Position{}
+                }
+
+                color := PieceColorWhite
+                if c >= 'a' && c <= 'z' {
+                    color = PieceColorBlack
+                }
+                board[y][x] = Piece{Kind: kind, Color: color}
+                x ++
+            }
+
+            if x > rowsAndColums {
+                return errors.New("rank " + rank + " has more than 8 squares"), Position{}
+            }
+        }
+
+        if x != rowsAndColums {
+            return errors.New("rank " + rank + " has " + strconv.Itoa(x) + " squares instead of 8"), Position{}
+        }
+    }
+
+    var turn PieceColor
+    switch fields[1] {
+        case "w":
+            turn = PieceColorWhite
+        case "b":
+            turn = PieceColorBlack
+        default:
+            return errors.New("side to move must be w or b, found " + fields[1]), Position{}
+    }
+
+    p := NewPosition(board, turn)
+
+    err, rights := parseCastlingRights(fields[2], &p)
+    if err != nil {
+        return err, Position{}
+    }
+    p.castlingRights = rights
+
+    if fields[3] != "-" {
+        err, sq := ParseSquare(fields[3])
+        if err != nil {
+            return errors.New("en passant square: " + err.Error()), Position{}
+        }
+
+        // the en passant square is behind a pawn of the player that just moved
+        row, behind := 2, sq + rowsAndColums
+        if turn == PieceColorBlack {
+            row, behind = 5, sq - rowsAndColums
+        }
+        if sq / rowsAndColums != row || p.squares[behind] != (Piece{Kind: PieceKindPawn, Color: turn.Opponent()}) {
+            return errors.New("en passant square " + fields[3] + " is not behind a pawn that just moved two squares"), Position{}
+        }
+        p.enPassant = sq
+    }
+
+    if len(fields) == 6 {
+        halfmoveClock, err := strconv.Atoi(fields[4])
+        if err != nil || halfmoveClock < 0 {
+            return errors.New("halfmove clock must be a number of at least 0, found " + fields[4]), Position{}
+        }
+
+        fullmoveNumber, err := strconv.Atoi(fields[5])
+        if err != nil || fullmoveNumber < 1 {
+            return errors.New("fullmove number must be a number of at least 1, found " + fields[5]), Position{}
+        }
+
+        p.halfmoveClock = halfmoveClock
+        p.fullmoveNumber = fullmoveNumber
+    }
+
+    return nil, p
+}
+
+// checks that a game can be played from the position
+// each side needs exactly one king, the side that just moved can not be in check and pawns can not be on the first or last rank
+// ParseFEN does not check these, so boards for moving pieces freely can be read as well
+func (p *Position) Validate() error {
+    kings := [2]int{}
+    names := [2]string{"white", "black"}
+
+    for sq, pc := range p.squares {
+        switch {
+            case pc.Kind == PieceKindKing:
+                kings[pc.Color] ++
+            case pc.Kind == PieceKindPawn && (sq < rowsAndColums || sq >= 56):
+                return errors.New("pawn on " + SquareName(sq) + ", pawns can not be on the first or last rank")
+        }
+    }
+
+    for color, count := range kings {
+        if count != 1 {
+            return errors.New(names[color] + " has " + strconv.Itoa(count) + " kings instead of 1")
+        }
+    }
+
+    if p.InCheck(p.turn.Opponent()) {
+        return errors.New(names[p.turn.Opponent()] + " is in check, but it is " + names[p.turn] + "'s turn")
+    }
+
+    return nil
+}
+
+// reads the castling field of a FEN, each right needs the king and rook on their starting squares
+func parseCastlingRights(field string, p *Position) (error, castlingRights) {
+    rights := castlingRights{}
+    if field == "-" {
+        return nil, rights
+    }
+
+    for i := 0; i < len(field); i++ {
+        var right *bool
+        var king, rook Piece
+        var rookSquare, kingSquare int
+
+        switch field[i] {
+            case 'K':
+                right, king, rook, kingSquare, rookSquare = &rights.whiteKingSide, kingWhite, rookWhite, squareE1, squareH1
+            case 'Q':
+                right, king, rook, kingSquare, rookSquare = &rights.whiteQueenSide, kingWhite, rookWhite, squareE1, squareA1
+            case 'k':
+                right, king, rook, kingSquare, rookSquare = &rights.blackKingSide, kingBlack, rookBlack, squareE8, squareH8
+            case 'q':
+                right, king, rook, kingSquare, rookSquare = &rights.blackQueenSide, kingBlack, rookBlack, squareE8, squareA8
+            default:
+                return errors.New("castling rights must be - or some of KQkq, found " + field), rights
+        }
+
+        if *right {
+            return errors.New("castling right " + string(field[i]) + " is given twice"), rights
+        }
+        if p.squares[kingSquare] != king || p.squares[rookSquare] != rook {
+            return errors.New("castling right " + string(field[i]) + " needs the king on " + SquareName(kingSquare) +
+                " and a rook on " + SquareName(rookSquare)), rights
+        }
+        *right = true
+    }
+
+    return nil, rights
+}
+
+// the letter of the piece in FEN, uppercase for white and lowercase for black
+func fenLetter(pc Piece) byte {
+    letter := pieceLetters[pc.Kind]
+    if pc.Color == PieceColorBlack {
+        letter += 'a' - 'A'
+    }
+    return letter
+}
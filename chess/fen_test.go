@@ -0,0 +1,77 @@
+package chess
+
+import "testing"
+
+func TestFENRoundTrip(t *testing.T) {
+    fens := []string{
+        "rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3",
+        "rnbqkbnr/pppp1ppp/8/8/3Pp3/8/PPP1PPPP/RNBQKBNR b Kq d3 0 3",
+        "8/8/8/8/8/8/8/8 w - - 0 1",
+    }
+    fens = append(fens, notationPositions...)
+
+    for _, fen := range fens {
+        err, p := ParseFEN(fen)
+        if err != nil {
+            t.Errorf("%s: %v", fen, err)
+            continue
+        }
+        if p.FEN() != fen {
+            t.Errorf("%s was written as %s", fen, p.FEN())
+        }
+    }
+}
+
+// the halfmove clock and fullmove number can be left out
+func TestParseFENFourFields(t *testing.T) {
+    err, p := ParseFEN("4k3/8/8/8/8/8/8/4K3 b - -")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if p.FEN() != "4k3/8/8/8/8/8/8/4K3 b - - 0 1" {
+        t.Errorf("got %s", p.FEN())
+    }
+}
+
+func TestParseFENErrors(t *testing.T) {
+    fens := []string{
+        "",
+        "4k3/8/8/8/8/8/8/4K3 w - - 0",
+        "4k3/8/8/8/8/8/8 w - - 0 1",
+        "4k3/8/8/8/8/8/8/4K2X w - - 0 1",
+        "4k3/9/8/8/8/8/8/4K3 w - - 0 1",
+        "4k3/7/8/8/8/8/8/4K3 w - - 0 1",
+        "4k3/8/8/8/8/8/8/4K3 x - - 0 1",
+        "4k3/8/8/8/8/8/8/4K3 w K - 0 1",
+        "4k3/8/8/8/8/8/8/4K3 w - e3 0 1",
+        "4k3/8/8/8/8/8/8/4K3 w - - -1 1",
+        "4k3/8/8/8/8/8/8/4K3 w - - 0 0",
+    }
+
+    for _, fen := range fens {
+        if err, _ := ParseFEN(fen); err == nil {
+            t.Errorf("%q was accepted", fen)
+        }
+    }
+}
+
+func TestValidate(t *testing.T) {
+    positions := []struct {
+        fen string
+        valid bool
+    }{
+        {StartingFEN, true},
+        {"4k3/4R3/8/8/8/8/8/4K3 w - - 0 1", false},
+        {"4k3/4R3/8/8/8/8/8/4K3 b - - 0 1", true},
+        {"8/8/8/8/8/8/8/8 w - - 0 1", false},
+        {"4k3/8/8/8/8/8/8/4K2P w - - 0 1", false},
+        {"4kk2/8/8/8/8/8/8/4K3 w - - 0 1", false},
+    }
+
+    for _, position := range positions {
+        _, p := ParseFEN(position.fen)
+        if err := p.Validate(); (err == nil) != position.valid {
+            t.Errorf("%s: got %v", position.fen, err)
+        }
+    }
+}
@@ -0,0 +1,169 @@
+package chess
+
+import "errors"
+
+/* results of a game, written the way PGN writes them */
+const (
+    ResultWhiteWins = "1-0"
+    ResultBlackWins = "0-1"
+    ResultDraw = "1/2-1/2"
+)
+
+// a game played from a starting position, it keeps the moves played so they can be taken back
+// and the positions reached so repetitions can be found
+// moves that are taken back stay in the move tree, so playing another move instead starts a variation
+type Game struct {
+    // the position the game started from
+    start Position
+    position Position
+    // the moves played so far, used for taking back moves
+    history []Undo
+    // the moves played so far in standard algebraic notation
+    san []string
+    // every position reached in the game, starting with the starting position
+    keys []string
+    // the tree of every move played, the root stands for the starting position
+    root *MoveNode
+    // the node of the last move played, the root before the first move
+    current *MoveNode
+}
+
+// whether the game is over, and if not whether the player to move can claim a draw
+type Status struct {
+    // the result of the game, empty while the game is still going
+    Result string
+    // why the game ended, for example checkmate or stalemate
+    Reason string
+    // the rule a draw can be claimed by, empty if the player to move can not claim a draw
+    DrawClaim string
+}
+
+func NewGame(p Position) Game {
+    root := &MoveNode{}
+
+    return Game{
+        start: p,
+        position: p,
+        keys: []string{p.key()},
+        root: root,
+        current: root,
+    }
+}
+
+// the current position of the game
+func (g *Game) Position() *Position {
+    return &g.position
+}
+
+// the position the game started from
+func (g *Game) StartPosition() Position {
+    return g.start
+}
+
+// the moves played so far in standard algebraic notation
+func (g *Game) SANMoves() []string {
+    return append([]string{}, g.san...)
+}
+
+// the moves played so far, in the order they were played
+func (g *Game) Moves() []Move {
+    moves := make([]Move, len(g.history))
+    for i, u := range g.history {
+        moves[i] = u.Move
+    }
+    return moves
+}
+
+// plays the move if it is one of the legal moves of the current position
+// only the squares and promotion of the move are used, the rest is filled in from the legal move
+// a move that was played from this position before is followed in the move tree, any other move is added to it
+func (g *Game) MakeMove(mv Move) (error, Undo) {
+    moves := g.position.LegalMoves()
+
+    for _, legal := range moves {
+        if legal.From == mv.From && legal.To == mv.To && legal.Promotion == mv.Promotion {
+            node := g.current.child(legal)
+            if node == nil {
+                node = &MoveNode{Move: legal, SAN: g.position.san(legal, moves), parent: g.current}
+                g.current.Children = append(g.current.Children, node)
+            }
+            g.san = append(g.san, node.SAN)
+
+            u := g.position.MakeMove(legal)
+            g.history = append(g.history, u)
+            g.keys = append(g.keys, g.position.key())
+            g.current = node
+            return nil, u
+        }
+    }
+    return errors.New("illegal move " + mv.String()), Undo{}
+}
+
+// takes back the last move, this also works after the game is over
+// the move stays in the move tree
+func (g *Game) UndoMove() (error, Undo) {
+    if len(g.history) == 0 {
+        return errors.New("no moves to take back"), Undo{}
+    }
+
+    u := g.history[len(g.history) - 1]
+    g.history = g.history[:len(g.history) - 1]
+    g.san = g.san[:len(g.san) - 1]
+    g.keys = g.keys[:len(g.keys) - 1]
+    g.current = g.current.parent
+
+    g.position.UnmakeMove(u)
+
+    return nil, u
+}
+
+// ends the game if the player to move has no legal moves left or the game is drawn
+// the game is drawn by stalemate, fivefold repetition, the seventy-five move rule or insufficient material,
+// threefold repetition and the fifty move rule only allow the player to claim a draw
+func (g *Game) Status() Status {
+    p := &g.position
+
+    if len(p.LegalMoves()) == 0 {
+        if !p.InCheck(p.turn) {
+            return Status{Result: ResultDraw, Reason: "stalemate"}
+        }
+        if p.turn == PieceColorWhite {
+            return Status{Result: ResultBlackWins, Reason: "checkmate"}
+        }
+        return Status{Result: ResultWhiteWins, Reason: "checkmate"}
+    }
+
+    repetitions := g.countRepetitions()
+
+    if repetitions >= 5 {
+        return Status{Result: ResultDraw, Reason: "fivefold repetition"}
+    }
+    if p.halfmoveClock >= 150 {
+        return Status{Result: ResultDraw, Reason: "seventy-five-move rule"}
+    }
+    if p.InsufficientMaterial() {
+        return Status{Result: ResultDraw, Reason: "insufficient material"}
+    }
+    if repetitions >= 3 {
+        return Status{DrawClaim: "threefold repetition"}
+    }
+    if p.halfmoveClock >= 100 {
+        return Status{DrawClaim: "fifty-move rule"}
+    }
+
+    return Status{}
+}
+
+// counts how many times the current position has occurred in the game
+func (g *Game) countRepetitions() int {
+    current := g.keys[len(g.keys) - 1]
+    count := 0
+
+    for _, key := range g.keys {
+        if key == current {
+            count ++
+        }
+    }
+
+    return count
+}
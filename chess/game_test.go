@@ -0,0 +1,56 @@
+package chess
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestStatus(t *testing.T) {
+    // the knights go out and back, which repeats the starting position after every four moves
+    shuffle := "Nf3 Nf6 Ng1 Ng8 "
+
+    games := []struct {
+        name string
+        fen string
+        moves string
+        status Status
+    }{
+        {"game going", StartingFEN, "e4 e5", Status{}},
+        {"checkmate", StartingFEN, "f3 e5 g4 Qh4", Status{Result: ResultBlackWins, Reason: "checkmate"}},
+        {"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "", Status{Result: ResultDraw, Reason: "stalemate"}},
+        {"twofold repetition", StartingFEN, shuffle, Status{}},
+        {"threefold repetition", StartingFEN, strings.Repeat(shuffle, 2), Status{DrawClaim: "threefold repetition"}},
+        {"fourfold repetition", StartingFEN, strings.Repeat(shuffle, 3), Status{DrawClaim: "threefold repetition"}},
+        {"fivefold repetition", StartingFEN, strings.Repeat(shuffle, 4), Status{Result: ResultDraw, Reason: "fivefold repetition"}},
+        {"99 halfmoves", "4k3/8/8/8/8/8/8/R3K3 w - - 99 80", "", Status{}},
+        {"fifty-move rule", "4k3/8/8/8/8/8/8/R3K3 w - - 99 80", "Ra2", Status{DrawClaim: "fifty-move rule"}},
+        {"fifty-move rule reset", "r3k3/8/8/8/8/8/8/R3K3 w - - 99 80", "Rxa8+", Status{}},
+        {"seventy-five-move rule", "4k3/8/8/8/8/8/8/R3K3 w - - 149 80", "Ra2", Status{Result: ResultDraw, Reason: "seventy-five-move rule"}},
+        {"checkmate on the last move", "7k/8/6K1/8/8/8/8/R7 w - - 149 80", "Ra8", Status{Result: ResultWhiteWins, Reason: "checkmate"}},
+        {"kings", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", "", Status{Result: ResultDraw, Reason: "insufficient material"}},
+        {"king and knight", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", "", Status{Result: ResultDraw, Reason: "insufficient material"}},
+        {"king and bishop", "4k3/8/8/8/8/8/8/4KB2 w - - 0 1", "", Status{Result: ResultDraw, Reason: "insufficient material"}},
+        {"bishops on the same color", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", "", Status{Result: ResultDraw, Reason: "insufficient material"}},
+        {"bishops on different colors", "2b1k3/8/8/8/8/8/8/2B1K3 w - - 0 1", "", Status{}},
+        {"two knights", "4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", "", Status{}},
+        {"king and pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", "", Status{}},
+    }
+
+    for _, game := range games {
+        err, p := ParseFEN(game.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", game.name, err)
+        }
+
+        g := NewGame(p)
+        for _, san := range strings.Fields(game.moves) {
+            if err := g.makeSAN(san); err != nil {
+                t.Fatalf("%s: %v", game.name, err)
+            }
+        }
+
+        if status := g.Status(); status != game.status {
+            t.Errorf("%s: got %+v, expected %+v", game.name, status, game.status)
+        }
+    }
+}
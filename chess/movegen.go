@@ -0,0 +1,231 @@
+package chess
+
+// the kinds of piece a pawn can be promoted to, in the order they are offered to the player
+var promotionKinds = []PieceKind{PieceKindQueen, PieceKindRook, PieceKindBishop, PieceKindKnight}
+
+// generates every legal move for the player whose turn it is
+func (p *Position) LegalMoves() []Move {
+    moves := p.pseudoLegalMoves(make([]Move, 0, 64))
+
+    us := p.turn
+    kings := p.pieces[us][PieceKindKing]
+    inCheck := p.InCheck(us)
+
+    // in double check no single move blocks or captures both attackers, so only the king can move
+    doubleCheck := false
+    if kings.count() == 1 {
+        k := kings
+        doubleCheck = p.attackCounts[us.Opponent()][popSquare(&k)] > 1
+    }
+
+    // only pieces on a line with the king can be pinned to it
+    var kingLines bitboard
+    for k := kings; k != 0; {
+        sq := popSquare(&k)
+        kingLines |= rookAttacks(sq, 0) | bishopAttacks(sq, 0)
+    }
+
+    // remove the moves that would leave the king of the moving player in check
+    legal := moves[:0]
+    for _, mv := range moves {
+        switch {
+            // with more than one king, moving one king can uncover an attack on another
+            case kings.count() > 1:
+                if p.checkIfLegal(mv) {
+                    legal = append(legal, mv)
+                }
+
+            // the attack maps see through the king, so a king can not step back along the line of an attack
+            case mv.Piece.Kind == PieceKindKing:
+                if p.attacks[us.Opponent()] & squareBit(mv.To) == 0 {
+                    legal = append(legal, mv)
+                }
+
+            // no other piece can get the king out of a double check
+            case doubleCheck:
+
+            // capturing en passant removes two pieces from the row of the king, which can uncover an attack
+            case inCheck || kingLines & squareBit(mv.From) != 0 || mv.Flags & FlagEnPassant != 0:
+                if p.checkIfLegal(mv) {
+                    legal = append(legal, mv)
+                }
+
+            default:
+                legal = append(legal, mv)
+        }
+    }
+
+    return legal
+}
+
+// generates the legal moves of both colors, the moves of the player whose turn it is come first
+// the other color can not capture en passant, as the double pawn push was not its opponent's last move
+func (p Position) FreeplayMoves() []Move {
+    moves := p.LegalMoves()
+
+    p.SetTurn(p.turn.Opponent())
+
+    return append(moves, p.LegalMoves()...)
+}
+
+// plays the move on the board and checks if the king of the moving player is attacked afterwards
+func (p *Position) checkIfLegal(mv Move) bool {
+    us := p.turn
+
+    u := p.movePieces(mv)
+    legal := !p.kingAttacked(us)
+    p.unmovePieces(u)
+
+    return legal
+}
+
+// appends every move the pieces of the player whose turn it is can make, including moves that leave the king in check
+func (p *Position) pseudoLegalMoves(moves []Move) []Move {
+    us := p.turn
+    own := p.occupied[us]
+    opponent := p.occupied[us.Opponent()]
+    occupied := own | opponent
+
+    moves = p.pawnMoves(moves, occupied, opponent)
+
+    knights := p.pieces[us][PieceKindKnight]
+    for knights != 0 {
+        from := popSquare(&knights)
+        moves = p.appendMoves(moves, from, knightAttacks[from] &^ own)
+    }
+
+    bishops := p.pieces[us][PieceKindBishop] | p.pieces[us][PieceKindQueen]
+    for bishops != 0 {
+        from := popSquare(&bishops)
+        moves = p.appendMoves(moves, from, bishopAttacks(from, occupied) &^ own)
+    }
+
+    rooks := p.pieces[us][PieceKindRook] | p.pieces[us][PieceKindQueen]
+    for rooks != 0 {
+        from := popSquare(&rooks)
+        moves = p.appendMoves(moves, from, rookAttacks(from, occupied) &^ own)
+    }
+
+    kings := p.pieces[us][PieceKindKing]
+    for kings != 0 {
+        from := popSquare(&kings)
+        moves = p.appendMoves(moves, from, kingAttacks[from] &^ own)
+    }
+
+    return p.castlingMoves(moves, occupied)
+}
+
+func (p *Position) pawnMoves(moves []Move, occupied bitboard, opponent bitboard) []Move {
+    us := p.turn
+
+    // white pawns move up the board and black pawns move down
+    forward := -rowsAndColums
+    startRow := rowsAndColums - 2
+    if us == PieceColorBlack {
+        forward = rowsAndColums
+        startRow = 1
+    }
+
+    targets := opponent
+    if p.enPassant != -1 {
+        targets |= squareBit(p.enPassant)
+    }
+
+    pawns := p.pieces[us][PieceKindPawn]
+    for pawns != 0 {
+        from := popSquare(&pawns)
+        to := from + forward
+
+        // a pawn on the last row can not move forward
+        if to < 0 || to >= 64 {
+            continue
+        }
+
+        if occupied & squareBit(to) == 0 {
+            moves = appendPawnMove(moves, Move{From: from, To: to, Piece: p.squares[from], Captured: NoPiece})
+
+            // both squares in front of the pawn have to be empty to move two squares
+            if from / rowsAndColums == startRow && occupied & squareBit(to + forward) == 0 {
+                moves = append(moves, Move{From: from, To: to + forward, Piece: p.squares[from], Captured: NoPiece, Flags: FlagDoublePush})
+            }
+        }
+
+        captures := pawnAttacks[us][from] & targets
+        for captures != 0 {
+            to := popSquare(&captures)
+            mv := Move{From: from, To: to, Piece: p.squares[from], Captured: p.squares[to], Flags: FlagCapture}
+
+            // the pawn captured en passant is beside the moving pawn
+            if to == p.enPassant {
+                mv.Captured = Piece{Kind: PieceKindPawn, Color: us.Opponent()}
+                mv.Flags |= FlagEnPassant
+            }
+
+            moves = appendPawnMove(moves, mv)
+        }
+    }
+
+    return moves
+}
+
+// appends a pawn move, a pawn reaching the first or last row can be promoted to any of the promotion kinds
+func appendPawnMove(moves []Move, mv Move) []Move {
+    row := mv.To / rowsAndColums
+    if row != 0 && row != rowsAndColums - 1 {
+        return append(moves, mv)
+    }
+
+    mv.Flags |= FlagPromotion
+    for _, kind := range promotionKinds {
+        mv.Promotion = kind
+        moves = append(moves, mv)
+    }
+    return moves
+}
+
+// castling moves the king two squares towards the rook
+// the squares between the king and rook must be empty, and the king can not be in check or pass through an attacked square
+func (p *Position) castlingMoves(moves []Move, occupied bitboard) []Move {
+    us := p.turn
+    opponent := us.Opponent()
+
+    king, rook, home := kingWhite, rookWhite, squareE1
+    if us == PieceColorBlack {
+        king, rook, home = kingBlack, rookBlack, squareE8
+    }
+
+    kingSide, queenSide := p.castlingRights.get(us)
+
+    attacked := p.attacks[opponent]
+
+    if p.squares[home] != king || !(kingSide || queenSide) || attacked & squareBit(home) != 0 {
+        return moves
+    }
+
+    if kingSide && p.squares[home + 3] == rook &&
+    occupied & (squareBit(home + 1) | squareBit(home + 2)) == 0 &&
+    attacked & (squareBit(home + 1) | squareBit(home + 2)) == 0 {
+        moves = append(moves, Move{From: home, To: home + 2, Piece: king, Captured: NoPiece, Flags: FlagKingSideCastle})
+    }
+
+    if queenSide && p.squares[home - 4] == rook &&
+    occupied & (squareBit(home - 1) | squareBit(home - 2) | squareBit(home - 3)) == 0 &&
+    attacked & (squareBit(home - 1) | squareBit(home - 2)) == 0 {
+        moves = append(moves, Move{From: home, To: home - 2, Piece: king, Captured: NoPiece, Flags: FlagQueenSideCastle})
+    }
+
+    return moves
+}
+
+// appends a move from the square to each of the targets
+func (p *Position) appendMoves(moves []Move, from int, targets bitboard) []Move {
+    for targets != 0 {
+        to := popSquare(&targets)
+        mv := Move{From: from, To: to, Piece: p.squares[from], Captured: p.squares[to]}
+        if mv.Captured.Kind != PieceKindNone {
+            mv.Flags = FlagCapture
+        }
+        moves = append(moves, mv)
+    }
+    return moves
+}
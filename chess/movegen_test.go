@@ -0,0 +1,87 @@
+package chess
+
+import "testing"
+
+func TestAttackCount(t *testing.T) {
+    squares := []struct {
+        fen string
+        square string
+        color PieceColor
+        count int
+    }{
+        {StartingFEN, "f3", PieceColorWhite, 3},
+        {StartingFEN, "d3", PieceColorWhite, 2},
+        {StartingFEN, "e4", PieceColorWhite, 0},
+        {StartingFEN, "f6", PieceColorBlack, 3},
+        {"4k3/6b1/5N2/8/8/8/8/4RK2 b - - 0 1", "e8", PieceColorWhite, 2},
+    }
+
+    for _, s := range squares {
+        _, p := ParseFEN(s.fen)
+        _, sq := ParseSquare(s.square)
+
+        if count := p.AttackCount(sq, s.color); count != s.count {
+            t.Errorf("%s: %s is attacked by %d pieces, expected %d", s.fen, s.square, count, s.count)
+        }
+    }
+}
+
+// only the king can move in a double check, even if another piece can capture one of the attackers
+func TestDoubleCheck(t *testing.T) {
+    _, p := ParseFEN("4k3/6b1/5N2/8/8/8/8/4RK2 b - - 0 1")
+
+    moves := p.LegalMoves()
+    if len(moves) == 0 {
+        t.Fatal("the king has no moves")
+    }
+    for _, mv := range moves {
+        if mv.Piece.Kind != PieceKindKing {
+            t.Errorf("%v is not a king move", mv)
+        }
+    }
+}
+
+// times the move generator on the perft positions, run with go test -bench . ./chess
+func BenchmarkLegalMoves(b *testing.B) {
+    for _, position := range perftPositions {
+        _, p := ParseFEN(position.fen)
+
+        b.Run(position.name, func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                p.LegalMoves()
+            }
+        })
+    }
+}
+
+// generates the legal moves by playing every move and checking if the king is attacked,
+// the slow path LegalMoves only takes for moves that can leave the king in check
+func BenchmarkLegalMovesCheckingEveryMove(b *testing.B) {
+    for _, position := range perftPositions {
+        _, p := ParseFEN(position.fen)
+
+        b.Run(position.name, func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                moves := p.pseudoLegalMoves(make([]Move, 0, 64))
+                legal := moves[:0]
+                for _, mv := range moves {
+                    if p.checkIfLegal(mv) {
+                        legal = append(legal, mv)
+                    }
+                }
+            }
+        })
+    }
+}
+
+// times making and taking back moves as well as generating them
+func BenchmarkPerft(b *testing.B) {
+    _, p := ParseFEN(StartingFEN)
+
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        Perft(&p, 3)
+    }
+}
@@ -0,0 +1,42 @@
+package chess
+
+type PerftResult struct {
+    Move Move
+    Nodes int
+}
+
+// counts the leaf nodes of the tree of legal moves to the given depth
+func Perft(p *Position, depth int) int {
+    if depth == 0 {
+        return 1
+    }
+
+    moves := p.LegalMoves()
+
+    // the moves themselves are the leaf nodes, so there is no need to play them
+    if depth == 1 {
+        return len(moves)
+    }
+
+    nodes := 0
+    for _, mv := range moves {
+        u := p.MakeMove(mv)
+        nodes += Perft(p, depth - 1)
+        p.UnmakeMove(u)
+    }
+
+    return nodes
+}
+
+// counts the leaf nodes below each legal move, the depth includes the move itself
+func Divide(p *Position, depth int) []PerftResult {
+    results := []PerftResult{}
+
+    for _, mv := range p.LegalMoves() {
+        u := p.MakeMove(mv)
+        results = append(results, PerftResult{mv, Perft(p, depth - 1)})
+        p.UnmakeMove(u)
+    }
+
+    return results
+}
@@ -0,0 +1,55 @@
+package chess
+
+import "testing"
+
+// the standard perft positions with the number of leaf nodes at each depth, starting at depth 1
+// see https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+    name string
+    fen string
+    nodes []int
+}{
+    {"start", StartingFEN, []int{20, 400, 8902, 197281, 4865609}},
+    {"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862, 4085603}},
+    {"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812, 43238, 674624}},
+    {"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467, 422333}},
+    {"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379, 2103487}},
+    {"position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []int{46, 2079, 89890, 3894594}},
+}
+
+// the deepest depth checked with go test -short
+const perftShortDepth = 3
+
+func TestPerft(t *testing.T) {
+    for _, position := range perftPositions {
+        err, p := ParseFEN(position.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", position.name, err)
+        }
+
+        for i, expected := range position.nodes {
+            depth := i + 1
+            if testing.Short() && depth > perftShortDepth {
+                break
+            }
+
+            if nodes := Perft(&p, depth); nodes != expected {
+                t.Errorf("%s depth %d: %d nodes, expected %d", position.name, depth, nodes, expected)
+            }
+        }
+    }
+}
+
+// the leaf nodes below the moves add up to the perft count
+func TestDivide(t *testing.T) {
+    _, p := ParseFEN(perftPositions[1].fen)
+
+    nodes := 0
+    for _, result := range Divide(&p, 2) {
+        nodes += result.Nodes
+    }
+
+    if nodes != perftPositions[1].nodes[1] {
+        t.Errorf("divide gave %d nodes, expected %d", nodes, perftPositions[1].nodes[1])
+    }
+}
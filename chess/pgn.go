@@ -0,0 +1,185 @@
+package chess
+
+import (
+    "strconv"
+    "strings"
+)
+
+// the longest line of movetext written to a PGN file
+const pgnLineLength = 80
+
+// a PGN tag pair, for example [White "Magnus Carlsen"]
+type Tag struct {
+    Name string
+    Value string
+}
+
+// the seven tag roster, every PGN game starts with these tags in this order
+var sevenTagRoster = []Tag{
+    {"Event", "?"},
+    {"Site", "?"},
+    {"Date", "????.??.??"},
+    {"Round", "?"},
+    {"White", "?"},
+    {"Black", "?"},
+    {"Result", "*"},
+}
+
+// writes the game in Portable Game Notation, with the variations in the move tree as recursive annotation variations
+// the seven tag roster comes first, tags of the roster that are not given are written as unknown,
+// and the result is taken from the Result tag, or from the status at the end of the main line if it is not given
+// games that do not start from the standard starting position get SetUp and FEN tags
+func (g *Game) PGN(tags []Tag) string {
+    var b strings.Builder
+
+    values := map[string]string{}
+    for _, tag := range tags {
+        values[tag.Name] = tag.Value
+    }
+
+    if _, ok := values["Result"]; !ok {
+        values["Result"] = "*"
+
+        end := NewGame(g.start)
+        for _, node := range g.MainLine() {
+            end.MakeMove(node.Move)
+        }
+        if result := end.Status().Result; result != "" {
+            values["Result"] = result
+        }
+    }
+
+    for _, tag := range sevenTagRoster {
+        value, ok := values[tag.Name]
+        if !ok || value == "" {
+            value = tag.Value
+        }
+        writeTag(&b, tag.Name, value)
+    }
+
+    start := g.start.FEN()
+    if start != StartingFEN {
+        writeTag(&b, "SetUp", "1")
+        writeTag(&b, "FEN", start)
+    }
+
+    for _, tag := range tags {
+        if !isRosterTag(tag.Name) && tag.Name != "SetUp" && tag.Name != "FEN" {
+            writeTag(&b, tag.Name, tag.Value)
+        }
+    }
+
+    b.WriteString("\n")
+    b.WriteString(wrapMovetext(g.movetext(values["Result"])))
+    b.WriteString("\n")
+
+    return b.String()
+}
+
+// the moves of the game with move numbers, followed by the result
+func (g *Game) movetext(result string) []string {
+    ply := (g.start.fullmoveNumber - 1) * 2 + int(g.start.turn)
+
+    // the comment of the root comes before the first move
+    tokens := appendComment(nil, g.root.Comment)
+
+    return append(g.lineMovetext(tokens, g.root, ply, true), result)
+}
+
+// appends the line of moves after the node, each move is followed by its variations in parentheses
+// ply counts the half moves from the first move of white, numbered tells if a move of black needs its move number
+func (g *Game) lineMovetext(tokens []string, n *MoveNode, ply int, numbered bool) []string {
+    for len(n.Children) != 0 {
+        main := n.Children[0]
+        tokens = appendMoveTokens(tokens, main, ply, numbered)
+
+        for _, variation := range n.Children[1:] {
+            start := len(tokens)
+            tokens = appendMoveTokens(tokens, variation, ply, true)
+            tokens = g.lineMovetext(tokens, variation, ply + 1, variation.Comment != "")
+
+            tokens[start] = "(" + tokens[start]
+            tokens[len(tokens) - 1] += ")"
+        }
+
+        // the line continues after a comment or variations with a move number
+        numbered = main.Comment != "" || len(n.Children) > 1
+        n = main
+        ply ++
+    }
+
+    return tokens
+}
+
+// appends the move with its move number, annotation glyphs and comments
+// moves of black only get a move number when numbered is set or a comment comes before them
+func appendMoveTokens(tokens []string, n *MoveNode, ply int, numbered bool) []string {
+    number := strconv.Itoa(ply / 2 + 1)
+
+    if n.CommentBefore != "" {
+        tokens = appendComment(tokens, n.CommentBefore)
+        numbered = true
+    }
+
+    if ply % 2 == 0 {
+        tokens = append(tokens, number + ".")
+    } else if numbered {
+        tokens = append(tokens, number + "...")
+    }
+    tokens = append(tokens, n.SAN)
+
+    for _, nag := range n.NAGs {
+        tokens = append(tokens, "$" + strconv.Itoa(nag))
+    }
+
+    return appendComment(tokens, n.Comment)
+}
+
+// appends the comment in braces, each word is a token so long comments are wrapped like the moves
+func appendComment(tokens []string, comment string) []string {
+    words := strings.Fields(strings.ReplaceAll(comment, "}", ")"))
+    if len(words) == 0 {
+        return tokens
+    }
+
+    words[0] = "{" + words[0]
+    words[len(words) - 1] += "}"
+
+    return append(tokens, words...)
+}
+
+// joins the tokens with spaces, starting a new line before a line gets longer than pgnLineLength
+func wrapMovetext(tokens []string) string {
+    s := ""
+    length := 0
+
+    for _, token := range tokens {
+        if length != 0 && length + 1 + len(token) > pgnLineLength {
+            s += "\n"
+            length = 0
+        }
+        if length != 0 {
+            s += " "
+            length ++
+        }
+        s += token
+        length += len(token)
+    }
+
+    return s + "\n"
+}
+
+func writeTag(b *strings.Builder, name string, value string) {
+    value = strings.ReplaceAll(value, "\\", "\\\\")
+    value = strings.ReplaceAll(value, "\"", "\\\"")
+    b.WriteString("[" + name + " \"" + value + "\"]\n")
+}
+
+func isRosterTag(name string) bool {
+    for _, tag := range sevenTagRoster {
+        if tag.Name == name {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,400 @@
+package chess
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// plays the moves written in SAN and separated by spaces
+func playSAN(t *testing.T, g *Game, moves string) {
+    for _, san := range strings.Fields(moves) {
+        if err := g.makeSAN(san); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
+
+func TestPGN(t *testing.T) {
+    _, p := ParseFEN(StartingFEN)
+    g := NewGame(p)
+    playSAN(t, &g, "e4 e5 Nf3 Nc6 Bb5 a6")
+
+    tags := []Tag{{"ECO", "C68"}, {"White", "Anna \"A\""}, {"Event", "Club"}, {"Black", "Ben"}}
+    expected := `[Event "Club"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Anna \"A\""]
+[Black "Ben"]
+[Result "*"]
+[ECO "C68"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 *
+
+`
+
+    if pgn := g.PGN(tags); pgn != expected {
+        t.Errorf("got\n%s\nexpected\n%s", pgn, expected)
+    }
+}
+
+// without a Result tag the result is found from the end of the main line
+func TestPGNResult(t *testing.T) {
+    _, p := ParseFEN(StartingFEN)
+    g := NewGame(p)
+    playSAN(t, &g, "f3 e5 g4 Qh4#")
+
+    if pgn := g.PGN(nil); !strings.Contains(pgn, "[Result \"0-1\"]") || !strings.HasSuffix(pgn, "Qh4# 0-1\n\n") {
+        t.Errorf("got\n%s", pgn)
+    }
+    if pgn := g.PGN([]Tag{{"Result", "1/2-1/2"}}); !strings.HasSuffix(pgn, "Qh4# 1/2-1/2\n\n") {
+        t.Errorf("got\n%s", pgn)
+    }
+}
+
+// games that do not start from the starting position get SetUp and FEN tags
+func TestPGNSetUp(t *testing.T) {
+    fen := "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"
+    _, p := ParseFEN(fen)
+    g := NewGame(p)
+    playSAN(t, &g, "Kd7 e4")
+
+    pgn := g.PGN(nil)
+    if !strings.Contains(pgn, "[Result \"*\"]\n[SetUp \"1\"]\n[FEN \"" + fen + "\"]\n") ||
+    !strings.Contains(pgn, "\n12... Kd7 13. e4 *\n") {
+        t.Errorf("got\n%s", pgn)
+    }
+}
+
+// the movetext is wrapped so no line is longer than 80 characters
+func TestPGNWrapping(t *testing.T) {
+    _, p := ParseFEN(StartingFEN)
+    g := NewGame(p)
+    playSAN(t, &g, strings.Repeat("Nf3 Nf6 Ng1 Ng8 ", 10))
+    g.Current().Comment = strings.Repeat("a long comment ", 10)
+
+    lines := strings.Split(g.PGN(nil), "\n")
+    for _, line := range lines {
+        if len(line) > pgnLineLength {
+            t.Errorf("%d characters: %s", len(line), line)
+        }
+    }
+    if len(lines) < 14 {
+        t.Errorf("the movetext was not wrapped:\n%s", strings.Join(lines, "\n"))
+    }
+}
+
+func TestParsePGNTags(t *testing.T) {
+    pgn := "[Event \"Club \\\"open\\\"\"] [Site \"a\\\\b\"]\n[ White  \"Anna\" ]\n\n1. e4 *\n"
+
+    err, games := ParsePGN(pgn)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := []Tag{{"Event", "Club \"open\""}, {"Site", "a\\b"}, {"White", "Anna"}}
+    if !reflect.DeepEqual(games[0].Tags, expected) {
+        t.Errorf("got %q, expected %q", games[0].Tags, expected)
+    }
+
+    invalid := []string{
+        "[Event \"a\"",
+        "[Event a]",
+        "[\"a\"]",
+        "[Event \"a\nb\"]",
+        "[Event \"a\" [Site \"b\"]",
+    }
+
+    for _, tag := range invalid {
+        if err, _ := ParsePGN(tag + "\n\n1. e4 *\n"); err == nil {
+            t.Errorf("%q was accepted", tag)
+        }
+    }
+}
+
+// the game termination marker is kept for games without a Result tag
+func TestParsePGNResult(t *testing.T) {
+    err, games := ParsePGN("1. e4 e5 1-0\n\n[Result \"0-1\"]\n\n1. d4 0-1\n\n[Event \"?\"]\n\n1. c4\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    results := []string{"1-0", "0-1", ""}
+    for i, result := range results {
+        if games[i].Result != result {
+            t.Errorf("game %d: got result %q, expected %q", i + 1, games[i].Result, result)
+        }
+    }
+}
+
+func TestParsePGNInvalidGlyphs(t *testing.T) {
+    movetexts := []string{"1. e4 $0 *", "1. e4 $256 *", "1. e4 $300 *", "1. e4 $-1 *", "1. e4 $ *", "$1 1. e4 *"}
+
+    for _, movetext := range movetexts {
+        if err, _ := ParsePGN(movetext); err == nil {
+            t.Errorf("%q was accepted", movetext)
+        }
+    }
+
+    err, games := ParsePGN("1. e4 $1 $255 *")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if nags := games[0].Game.MainLine()[0].NAGs; !reflect.DeepEqual(nags, []int{1, 255}) {
+        t.Errorf("got glyphs %v, expected [1 255]", nags)
+    }
+}
+
+// errors name the game and the move number of the move that could not be read
+func TestParsePGNErrors(t *testing.T) {
+    pgns := []struct {
+        pgn string
+        err string
+    }{
+        {"1. e4 e5 2. Ke3 *", "game 1: move 2: illegal move Ke3"},
+        {"1. e4 Ke7 *", "game 1: move 1...: illegal move Ke7"},
+        {"1. Nf3 a6 2. Nc3 a5 3. Ne4 a4 4. Ng5 *", "game 1: move 4: ambiguous move Ng5"},
+        {"1. e4 e5 *\n\n1. d4 d5 2. c4 Nxc4 *", "game 2: move 2...: illegal move Nxc4"},
+        {"1. e4 (1. d4 *", "game 1: missing )"},
+        {"1. e4 ) *", "game 1: unexpected )"},
+        {"1. e4 {comment *", "game 1: missing }"},
+        {"", "no games found"},
+    }
+
+    for _, p := range pgns {
+        err, _ := ParsePGN(p.pgn)
+        if err == nil || !strings.HasPrefix(err.Error(), p.err) {
+            t.Errorf("%q: got %v, expected %s", p.pgn, err, p.err)
+        }
+    }
+}
+
+// a file with several games is split into games where the tags of the next game start,
+// and after the result of a game without tags
+func TestParsePGNGames(t *testing.T) {
+    pgn := `[Event "first"]
+[White "Anna"]
+
+1. e4 e5 2. Nf3 1-0
+
+[Event "second"]
+
+1. d4 d5
+
+[Event "third"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e4 Kd7 *
+1. c4 1/2-1/2
+`
+
+    err, games := ParsePGN(pgn)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := []struct {
+        event string
+        moves int
+        fen string
+    }{
+        {"first", 3, "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"},
+        {"second", 2, "rnbqkbnr/ppp1pppp/8/3p4/3P4/8/PPP1PPPP/RNBQKBNR w KQkq d6 0 2"},
+        {"third", 2, "8/3k4/8/8/4P3/8/8/4K3 w - - 1 2"},
+        {"", 1, "rnbqkbnr/pppppppp/8/8/2P5/8/PP1PPPPP/RNBQKBNR b KQkq c3 0 1"},
+    }
+
+    if len(games) != len(expected) {
+        t.Fatalf("got %d games, expected %d", len(games), len(expected))
+    }
+    for i, e := range expected {
+        g := games[i].Game
+        if games[i].Tag("Event") != e.event || len(g.MainLine()) != e.moves || g.Position().FEN() != e.fen {
+            t.Errorf("game %d: got event %q, %d moves and %s", i + 1, games[i].Tag("Event"), len(g.MainLine()), g.Position().FEN())
+        }
+    }
+}
+
+// the seven tag roster with unknown values, as written for a game without tags
+const unknownRoster = `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+
+`
+
+// reads the game and checks that writing it gives the same text
+func checkPGNRoundTrip(t *testing.T, pgn string) {
+    err, games := ParsePGN(pgn)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if written := games[0].Game.PGN(games[0].Tags); written != pgn {
+        t.Errorf("got\n%s\nexpected\n%s", written, pgn)
+    }
+}
+
+// variations inside variations are read into the move tree and written back the same
+func TestPGNVariationsRoundTrip(t *testing.T) {
+    checkPGNRoundTrip(t, unknownRoster +
+        "1. e4 e5 (1... c5 2. Nf3 (2. Nc3 Nc6 (2... e6 3. g3)) 2... d6) (1... e6) 2. Nf3\nNc6 *\n\n")
+}
+
+func TestMoveTree(t *testing.T) {
+    _, p := ParseFEN(StartingFEN)
+    g := NewGame(p)
+    playSAN(t, &g, "e4 e5 Nf3")
+
+    e4 := g.MainLine()[0]
+    e5 := g.MainLine()[1]
+    nf3 := g.MainLine()[2]
+
+    // playing another move after going back starts a variation
+    if err := g.GoTo(e4); err != nil {
+        t.Fatal(err)
+    }
+    playSAN(t, &g, "c5")
+    c5 := g.Current()
+
+    if g.OnMainLine() || !reflect.DeepEqual(e4.Children, []*MoveNode{e5, c5}) {
+        t.Fatalf("c5 is not a variation of e5")
+    }
+
+    // playing a move that was played before follows it
+    g.GoTo(e4)
+    playSAN(t, &g, "e5")
+    if g.Current() != e5 || len(e4.Children) != 2 {
+        t.Errorf("e5 was added again")
+    }
+
+    // going to a move plays the moves leading to it, also from another variation
+    if err := g.GoTo(c5); err != nil {
+        t.Fatal(err)
+    }
+    if err := g.GoTo(nf3); err != nil {
+        t.Fatal(err)
+    }
+    if g.Current() != nf3 || !reflect.DeepEqual(g.SANMoves(), []string{"e4", "e5", "Nf3"}) {
+        t.Errorf("got moves %v", g.SANMoves())
+    }
+    if g.Position().FEN() != "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2" {
+        t.Errorf("got position %s", g.Position().FEN())
+    }
+
+    _, other := ParseFEN(StartingFEN)
+    otherGame := NewGame(other)
+    playSAN(t, &otherGame, "e4")
+    if err := g.GoTo(otherGame.Current()); err == nil {
+        t.Errorf("went to a move of another game")
+    }
+
+    // promoting the variation of c5 makes it the main line
+    g.GoTo(c5)
+    if err := g.PromoteVariation(); err != nil {
+        t.Fatal(err)
+    }
+    if !g.OnMainLine() || !reflect.DeepEqual(e4.Children, []*MoveNode{c5, e5}) {
+        t.Errorf("c5 was not promoted")
+    }
+    if err := g.PromoteVariation(); err == nil {
+        t.Errorf("promoted a move on the main line")
+    }
+
+    // deleting from inside a variation deletes the whole variation and goes to the move before it
+    g.GoTo(nf3)
+    if err := g.DeleteVariation(); err != nil {
+        t.Fatal(err)
+    }
+    if g.Current() != e4 || !reflect.DeepEqual(e4.Children, []*MoveNode{c5}) {
+        t.Errorf("the variation of e5 was not deleted")
+    }
+
+    // on the main line the move and the moves after it are deleted
+    g.GoTo(c5)
+    if err := g.DeleteVariation(); err != nil {
+        t.Fatal(err)
+    }
+    if g.Current() != e4 || len(e4.Children) != 0 {
+        t.Errorf("c5 was not deleted")
+    }
+
+    if err := g.DeleteVariation(); err != nil {
+        t.Fatal(err)
+    }
+    if g.Current() != g.Root() || len(g.Root().Children) != 0 {
+        t.Errorf("e4 was not deleted")
+    }
+    if err := g.DeleteVariation(); err == nil {
+        t.Errorf("deleted a move from a game without moves")
+    }
+}
+
+// comments on the game, on moves and at the start of variations, and glyphs, are written back the same
+func TestPGNCommentsRoundTrip(t *testing.T) {
+    checkPGNRoundTrip(t, unknownRoster +
+        "{A game} 1. e4 $1 {Best by test} 1... e5 ({Sicilian} 1... c5 $5 {sharp} 2. Nf3\n" +
+        "({Closed} 2. Nc3 $2 $18)) 2. Nf3 $14 *\n\n")
+}
+
+// comments and glyphs are kept on the moves they belong to
+func TestParsePGNComments(t *testing.T) {
+    err, games := ParsePGN("{on the game} 1. e4!? {a} {b} e5 ; to the end of the line\n" +
+        "2. Nf3 ({first} {of the variation} 2. Nc3 ?! $14) 2... Nc6 *")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    g := games[0].Game
+    e4, e5, nf3 := g.MainLine()[0], g.MainLine()[1], g.MainLine()[2]
+    nc3 := e5.Children[1]
+
+    nodes := []struct {
+        node *MoveNode
+        comment string
+        commentBefore string
+        nags []int
+    }{
+        {g.Root(), "on the game", "", nil},
+        {e4, "a b", "", []int{5}},
+        {e5, "to the end of the line", "", nil},
+        {nf3, "", "", nil},
+        {nc3, "", "first of the variation", []int{6, 14}},
+    }
+
+    for _, n := range nodes {
+        if n.node.Comment != n.comment || n.node.CommentBefore != n.commentBefore || !reflect.DeepEqual(n.node.NAGs, n.nags) {
+            t.Errorf("%s: got comment %q, comment before %q and glyphs %v", n.node.SAN, n.node.Comment, n.node.CommentBefore, n.node.NAGs)
+        }
+    }
+}
+
+// glyphs of the same group replace each other, like ! and ?, and other glyphs are added
+func TestToggleNAG(t *testing.T) {
+    n := &MoveNode{SAN: "e4"}
+
+    toggles := []struct {
+        nag int
+        nags []int
+        annotated string
+    }{
+        {1, []int{1}, "e4!"},
+        {2, []int{2}, "e4?"},
+        {14, []int{2, 14}, "e4? +="},
+        {2, []int{14}, "e4 +="},
+        {15, []int{15}, "e4 =+"},
+        {40, []int{15, 40}, "e4 =+ $40"},
+    }
+
+    for _, toggle := range toggles {
+        n.ToggleNAG(toggle.nag)
+        if !reflect.DeepEqual(n.NAGs, toggle.nags) || n.Annotated() != toggle.annotated {
+            t.Errorf("after $%d: got glyphs %v written as %q", toggle.nag, n.NAGs, n.Annotated())
+        }
+    }
+}
@@ -0,0 +1,353 @@
+package chess
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// a game read from a PGN file
+type PGNGame struct {
+    Tags []Tag
+    // the game with all its moves played
+    Game Game
+    // the game termination marker at the end of the movetext, 1-0, 0-1, 1/2-1/2 or *
+    // it is empty if the movetext ends without one
+    Result string
+}
+
+// the value of the tag, or an empty string if the game does not have the tag
+func (g PGNGame) Tag(name string) string {
+    for _, tag := range g.Tags {
+        if tag.Name == name {
+            return tag.Value
+        }
+    }
+    return ""
+}
+
+// reads every game in a PGN file
+// each move is looked up among the legal moves of the position it is played in,
+// errors tell which game and move could not be read
+func ParsePGN(pgn string) (error, []PGNGame) {
+    r := pgnReader{s: pgn}
+    games := []PGNGame{}
+
+    for {
+        r.skipSpace()
+        if r.done() {
+            break
+        }
+
+        err, game := r.readGame()
+        if err != nil {
+            return errors.New("game " + strconv.Itoa(len(games) + 1) + ": " + err.Error()), nil
+        }
+        games = append(games, game)
+    }
+
+    if len(games) == 0 {
+        return errors.New("no games found"), nil
+    }
+
+    return nil, games
+}
+
+// the annotation glyphs of the marks that can be written right after a move
+var suffixNAGs = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// reads PGN text one token at a time
+type pgnReader struct {
+    s string
+    i int
+}
+
+func (r *pgnReader) done() bool {
+    return r.i >= len(r.s)
+}
+
+// skips spaces, and lines starting with % which PGN uses for escaping to other programs
+func (r *pgnReader) skipSpace() {
+    for !r.done() {
+        c := r.s[r.i]
+
+        if c == '%' && (r.i == 0 || r.s[r.i - 1] == '\n') {
+            r.skipLine()
+        } else if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+            r.i ++
+        } else {
+            return
+        }
+    }
+}
+
+func (r *pgnReader) skipLine() {
+    for !r.done() && r.s[r.i] != '\n' {
+        r.i ++
+    }
+}
+
+// reads the tag pairs and movetext of one game
+func (r *pgnReader) readGame() (error, PGNGame) {
+    game := PGNGame{}
+
+    for {
+        r.skipSpace()
+        if r.done() || r.s[r.i] != '[' {
+            break
+        }
+
+        err, tag := r.readTag()
+        if err != nil {
+            return err, game
+        }
+        game.Tags = append(game.Tags, tag)
+    }
+
+    start := StartingFEN
+    if fen := game.Tag("FEN"); fen != "" {
+        start = fen
+    }
+
+    err, p := ParseFEN(start)
+    if err != nil {
+        return errors.New("FEN tag: " + err.Error()), game
+    }
+    game.Game = NewGame(p)
+
+    err, game.Result = r.readMoves(&game.Game, 0)
+    return err, game
+}
+
+// reads moves into the game until the end of the game, or the end of the variation when depth is above 0
+// variations are added to the move tree of the game, the game termination marker that ends the game is returned
+func (r *pgnReader) readMoves(g *Game, depth int) (error, string) {
+    // comments at the start of a variation come before its first move
+    before := depth != 0
+    comment := ""
+
+    for {
+        r.skipSpace()
+
+        // a game without a result ends where the tags of the next game start
+        if r.done() || r.s[r.i] == '[' {
+            if depth != 0 {
+                return errors.New("missing )"), ""
+            }
+            return nil, ""
+        }
+
+        token := r.readToken()
+
+        switch {
+            case token == "1-0" || token == "0-1" || token == "1/2-1/2" || token == "*":
+                if depth != 0 {
+                    return errors.New("missing )"), ""
+                }
+                return nil, token
+
+            // comments belong to the move before them, or to the root before the first move
+            case token == "{":
+                end := strings.IndexByte(r.s[r.i:], '}')
+                if end == -1 {
+                    return errors.New("missing }"), ""
+                }
+                if before {
+                    comment += " " + r.s[r.i : r.i + end]
+                } else {
+                    g.current.addComment(r.s[r.i : r.i + end])
+                }
+                r.i += end + 1
+
+            case token == ";":
+                start := r.i
+                r.skipLine()
+                if before {
+                    comment += " " + r.s[start:r.i]
+                } else {
+                    g.current.addComment(r.s[start:r.i])
+                }
+
+            // a variation is played instead of the move before it
+            case token == "(":
+                current := g.current
+                if err, _ := g.UndoMove(); err != nil {
+                    return errors.New("variation before the first move"), ""
+                }
+                if err, _ := r.readMoves(g, depth + 1); err != nil {
+                    return err, ""
+                }
+                g.GoTo(current)
+
+            case token == ")":
+                if depth == 0 {
+                    return errors.New("unexpected )"), ""
+                }
+                return nil, ""
+
+            // glyphs are numbered from 1 to 255, $0 is the null annotation
+            case token[0] == '$':
+                nag, err := strconv.Atoi(token[1:])
+                if err != nil || nag < 1 || nag > 255 || g.current == g.root {
+                    return errors.New("invalid annotation " + token), ""
+                }
+                g.current.addNAG(nag)
+
+            // marks written apart from the move
+            case suffixNAGs[token] != 0 && g.current != g.root:
+                g.current.addNAG(suffixNAGs[token])
+
+            case isMoveNumber(token):
+                // the move numbers follow from the moves
+
+            default:
+                if err := g.makeSAN(token); err != nil {
+                    return err, ""
+                }
+                if before {
+                    g.current.CommentBefore = strings.Join(strings.Fields(comment), " ")
+                    before = false
+                }
+
+                // annotations written after the move, like e4!?
+                suffix := token[len(strings.TrimRight(token, "!?")):]
+                if nag, ok := suffixNAGs[suffix]; ok {
+                    g.current.addNAG(nag)
+                }
+        }
+    }
+}
+
+// reads a tag pair like [White "Magnus Carlsen"], a line can have more than one tag pair
+func (r *pgnReader) readTag() (error, Tag) {
+    start := r.i
+    r.i ++
+    r.skipSpace()
+
+    nameStart := r.i
+    for !r.done() && isSymbolCharacter(r.s[r.i]) {
+        r.i ++
+    }
+    name := r.s[nameStart:r.i]
+
+    r.skipSpace()
+    if name == "" || r.done() || r.s[r.i] != '"' {
+        return r.invalidTag(start), Tag{}
+    }
+    r.i ++
+
+    // a backslash escapes a quote or a backslash, the value ends at the first quote that is not escaped
+    value := []byte{}
+    for {
+        if r.done() || r.s[r.i] == '\n' {
+            return r.invalidTag(start), Tag{}
+        }
+
+        c := r.s[r.i]
+        r.i ++
+        if c == '"' {
+            break
+        }
+        if c == '\\' && !r.done() && (r.s[r.i] == '"' || r.s[r.i] == '\\') {
+            c = r.s[r.i]
+            r.i ++
+        }
+        value = append(value, c)
+    }
+
+    r.skipSpace()
+    if r.done() || r.s[r.i] != ']' {
+        return r.invalidTag(start), Tag{}
+    }
+    r.i ++
+
+    return nil, Tag{Name: name, Value: string(value)}
+}
+
+// the error for a tag pair that could not be read, with the rest of the line the tag starts on
+func (r *pgnReader) invalidTag(start int) error {
+    line := r.s[start:]
+    if end := strings.IndexByte(line, '\n'); end != -1 {
+        line = line[:end]
+    }
+    return errors.New("invalid tag " + strings.TrimSpace(line))
+}
+
+// checks if the character can be part of a tag name, which PGN calls a symbol
+func isSymbolCharacter(c byte) bool {
+    return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("_+#=:-", c) != -1
+}
+
+// reads the next token of movetext, a move number with its dots is one token and the move after it another
+func (r *pgnReader) readToken() string {
+    start := r.i
+    c := r.s[r.i]
+
+    if c == '{' || c == '}' || c == '(' || c == ')' || c == ';' {
+        r.i ++
+        return string(c)
+    }
+
+    for !r.done() {
+        c = r.s[r.i]
+        if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '{' || c == '(' || c == ')' || c == ';' {
+            break
+        }
+
+        r.i ++
+
+        // the number in 12.e4 is cut off from the move
+        if c == '.' && unicode.IsDigit(rune(r.s[start])) {
+            for !r.done() && r.s[r.i] == '.' {
+                r.i ++
+            }
+            break
+        }
+    }
+
+    return r.s[start:r.i]
+}
+
+// plays a move written in SAN, the error names the move number
+func (g *Game) makeSAN(san string) error {
+    p := &g.position
+
+    number := strconv.Itoa(p.fullmoveNumber)
+    if p.turn == PieceColorBlack {
+        number += "..."
+    }
+
+    err, mv := p.ParseSAN(san)
+    if err != nil {
+        return errors.New("move " + number + ": " + err.Error())
+    }
+
+    err, _ = g.MakeMove(mv)
+    return err
+}
+
+// checks if the token is a move number like 12. or 12..., or only the dots
+func isMoveNumber(token string) bool {
+    return strings.TrimRight(strings.TrimLeft(token, "0123456789"), ".") == "" && token != ""
+}
+
+// adds the comment to the comment of the move, comments of a move are joined with a space
+func (n *MoveNode) addComment(comment string) {
+    comment = strings.Join(strings.Fields(comment), " ")
+
+    if n.Comment != "" && comment != "" {
+        n.Comment += " "
+    }
+    n.Comment += comment
+}
+
+// adds the annotation glyph if the move does not have it yet
+func (n *MoveNode) addNAG(nag int) {
+    for _, other := range n.NAGs {
+        if other == nag {
+            return
+        }
+    }
+    n.ToggleNAG(nag)
+}
@@ -0,0 +1,80 @@
+// package chess implements the rules of chess: positions, legal move generation, making and taking back moves,
+// the end of the game, and reading and writing positions and moves in FEN and SAN
+package chess
+
+type PieceKind uint8
+
+// colors are used as indexes into the bitboards of a position
+type PieceColor uint8
+
+const (
+    PieceKindNone PieceKind = iota
+    PieceKindPawn
+    PieceKindKnight
+    PieceKindBishop
+    PieceKindRook
+    PieceKindQueen
+    PieceKindKing
+)
+
+const (
+    PieceColorWhite PieceColor = iota
+    PieceColorBlack
+    PieceColorNone
+)
+
+type Piece struct {
+    Kind PieceKind
+    Color PieceColor
+}
+
+// the piece on an empty square
+var NoPiece = Piece{Kind: PieceKindNone, Color: PieceColorNone}
+
+// the letters used for the kinds of piece in FEN and SAN
+var pieceLetters = map[PieceKind]byte{
+    PieceKindPawn: 'P',
+    PieceKindKnight: 'N',
+    PieceKindBishop: 'B',
+    PieceKindRook: 'R',
+    PieceKindQueen: 'Q',
+    PieceKindKing: 'K',
+}
+
+/* pieces used internally for castling */
+var (
+    kingWhite = Piece{Kind: PieceKindKing, Color: PieceColorWhite}
+    rookWhite = Piece{Kind: PieceKindRook, Color: PieceColorWhite}
+    kingBlack = Piece{Kind: PieceKindKing, Color: PieceColorBlack}
+    rookBlack = Piece{Kind: PieceKindRook, Color: PieceColorBlack}
+)
+
+// the uppercase letter of the kind of piece, for example N for a knight
+func (k PieceKind) Letter() string {
+    letter, ok := pieceLetters[k]
+    if !ok {
+        return ""
+    }
+    return string(letter)
+}
+
+// returns the kind of piece for an uppercase or lowercase letter
+func pieceKindOf(letter byte) (PieceKind, bool) {
+    if letter >= 'a' && letter <= 'z' {
+        letter -= 'a' - 'A'
+    }
+
+    for kind, l := range pieceLetters {
+        if l == letter {
+            return kind, true
+        }
+    }
+    return PieceKindNone, false
+}
+
+func (c PieceColor) Opponent() PieceColor {
+    if c == PieceColorWhite {
+        return PieceColorBlack
+    }
+    return PieceColorWhite
+}
@@ -0,0 +1,355 @@
+package chess
+
+import (
+    "fmt"
+    "strconv"
+)
+
+// a chess position, the board is kept as bitboards for generating moves and as a piece per square for drawing
+// squares are numbered from 0 for a8 to 63 for h1
+type Position struct {
+    // the squares occupied by each kind of piece, indexed by color and kind
+    pieces [2][7]bitboard
+    // the squares occupied by each color
+    occupied [2]bitboard
+    // the piece on each square
+    squares [64]Piece
+
+    // the color of the player that moves next
+    turn PieceColor
+    // which castling moves each color is still allowed to make
+    castlingRights castlingRights
+    // the square a pawn can capture en passant on, -1 if the last move was not a double pawn push
+    enPassant int
+    // number of moves since the last pawn move or capture, each player's move counts as one
+    halfmoveClock int
+    // the number of the move being played, it starts at 1 and goes up after each move by black
+    fullmoveNumber int
+
+    // the squares attacked by each color, kept up to date by MakeMove and UnmakeMove
+    attacks [2]bitboard
+    // the number of pieces of each color attacking each square
+    attackCounts [2][64]uint8
+}
+
+// a move of the piece on the square From to the square To
+type Move struct {
+    From int
+    To int
+    // the piece that moves, for promotions this is the pawn
+    Piece Piece
+    // the piece that is captured, NoPiece if the move is not a capture
+    Captured Piece
+    // the kind of piece a pawn is promoted to, PieceKindNone if the move is not a promotion
+    Promotion PieceKind
+    Flags MoveFlags
+}
+
+// what kind of move a move is, a move can have more than one flag, for example a capture that promotes a pawn
+type MoveFlags uint8
+
+const (
+    FlagCapture MoveFlags = 1 << iota
+    // a pawn capturing en passant, the captured pawn is not on the square moved to
+    FlagEnPassant
+    // a pawn moving two squares from its starting row
+    FlagDoublePush
+    FlagKingSideCastle
+    FlagQueenSideCastle
+    FlagPromotion
+)
+
+// everything needed to take back a move and restore the position exactly as it was
+type Undo struct {
+    Move Move
+
+    turn PieceColor
+    castlingRights castlingRights
+    enPassant int
+    halfmoveClock int
+    fullmoveNumber int
+
+    attacks [2]bitboard
+    attackCounts [2][64]uint8
+}
+
+type castlingRights struct {
+    whiteKingSide bool
+    whiteQueenSide bool
+    blackKingSide bool
+    blackQueenSide bool
+}
+
+/* starting squares of the kings and rooks, used for castling */
+const (
+    squareA8 = 0
+    squareE8 = 4
+    squareH8 = 7
+    squareA1 = 56
+    squareE1 = 60
+    squareH1 = 63
+)
+
+// creates a position from a board, a color can only castle if the king and rook are on their starting squares
+// row 0 of the board is the eighth rank
+func NewPosition(board [8][8]Piece, turn PieceColor) Position {
+    p := Position{
+        turn: turn,
+        enPassant: -1,
+        fullmoveNumber: 1,
+    }
+
+    for sq := 0; sq < 64; sq++ {
+        c := coordinateOf(sq)
+        p.squares[sq] = NoPiece
+        if board[c.y][c.x].Kind != PieceKindNone {
+            p.put(sq, board[c.y][c.x])
+        }
+    }
+
+    p.castlingRights = castlingRights{
+        whiteKingSide: p.squares[squareE1] == kingWhite && p.squares[squareH1] == rookWhite,
+        whiteQueenSide: p.squares[squareE1] == kingWhite && p.squares[squareA1] == rookWhite,
+        blackKingSide: p.squares[squareE8] == kingBlack && p.squares[squareH8] == rookBlack,
+        blackQueenSide: p.squares[squareE8] == kingBlack && p.squares[squareA8] == rookBlack,
+    }
+
+    p.updateAttacks()
+
+    return p
+}
+
+func (p *Position) PieceAt(sq int) Piece {
+    return p.squares[sq]
+}
+
+// the color of the player that moves next
+func (p *Position) Turn() PieceColor {
+    return p.turn
+}
+
+// makes it the turn of the color, used for moving pieces freely
+// when the turn changes, no pawn can be captured en passant
+func (p *Position) SetTurn(color PieceColor) {
+    if color != p.turn {
+        p.turn = color
+        p.enPassant = -1
+    }
+}
+
+// the number of moves by either player since the last pawn move or capture
+func (p *Position) HalfmoveClock() int {
+    return p.halfmoveClock
+}
+
+// the number of the move being played, it starts at 1 and goes up after each move by black
+func (p *Position) FullmoveNumber() int {
+    return p.fullmoveNumber
+}
+
+func (p *Position) put(sq int, pc Piece) {
+    p.squares[sq] = pc
+    p.pieces[pc.Color][pc.Kind] |= squareBit(sq)
+    p.occupied[pc.Color] |= squareBit(sq)
+}
+
+// removes the piece on the square and returns it
+func (p *Position) remove(sq int) Piece {
+    pc := p.squares[sq]
+    if pc.Kind == PieceKindNone {
+        return pc
+    }
+
+    p.squares[sq] = NoPiece
+    p.pieces[pc.Color][pc.Kind] &^= squareBit(sq)
+    p.occupied[pc.Color] &^= squareBit(sq)
+
+    return pc
+}
+
+// plays the move and returns what is needed to take it back with UnmakeMove
+// the move is not checked, it has to be one of the moves returned by LegalMoves
+func (p *Position) MakeMove(mv Move) Undo {
+    u := p.movePieces(mv)
+    p.updateAttacks()
+    return u
+}
+
+// takes back the move, this has to be the last move made on the position
+func (p *Position) UnmakeMove(u Undo) {
+    p.unmovePieces(u)
+    p.attacks = u.attacks
+    p.attackCounts = u.attackCounts
+}
+
+// plays the move without updating the attack maps
+// when castling the rook is moved as well, and capturing en passant removes the pawn beside the moving pawn
+func (p *Position) movePieces(mv Move) Undo {
+    u := Undo{
+        Move: mv,
+        turn: p.turn,
+        castlingRights: p.castlingRights,
+        enPassant: p.enPassant,
+        halfmoveClock: p.halfmoveClock,
+        fullmoveNumber: p.fullmoveNumber,
+        attacks: p.attacks,
+        attackCounts: p.attackCounts,
+    }
+
+    p.remove(mv.From)
+    p.remove(capturedSquare(mv))
+
+    if mv.Flags & FlagPromotion != 0 {
+        p.put(mv.To, Piece{Kind: mv.Promotion, Color: mv.Piece.Color})
+    } else {
+        p.put(mv.To, mv.Piece)
+    }
+
+    // when castling the rook moves to the square the king passed
+    if mv.Flags & FlagKingSideCastle != 0 {
+        p.put(mv.To - 1, p.remove(mv.To + 1))
+    } else if mv.Flags & FlagQueenSideCastle != 0 {
+        p.put(mv.To + 1, p.remove(mv.To - 2))
+    }
+
+    p.updateCastlingRights(mv.From)
+    p.updateCastlingRights(mv.To)
+
+    // a pawn moving two squares can be captured en passant on the square it skipped
+    p.enPassant = -1
+    if mv.Flags & FlagDoublePush != 0 {
+        p.enPassant = (mv.From + mv.To) / 2
+    }
+
+    // the clock for the fifty move rule is reset by pawn moves and captures
+    if mv.Piece.Kind == PieceKindPawn || mv.Flags & FlagCapture != 0 {
+        p.halfmoveClock = 0
+    } else {
+        p.halfmoveClock ++
+    }
+
+    if p.turn == PieceColorBlack {
+        p.fullmoveNumber ++
+    }
+
+    p.turn = p.turn.Opponent()
+
+    return u
+}
+
+// takes back a move played with movePieces, the attack maps are not restored
+func (p *Position) unmovePieces(u Undo) {
+    mv := u.Move
+    p.remove(mv.To)
+
+    // move the rook back when castling
+    if mv.Flags & FlagKingSideCastle != 0 {
+        p.put(mv.To + 1, p.remove(mv.To - 1))
+    } else if mv.Flags & FlagQueenSideCastle != 0 {
+        p.put(mv.To - 2, p.remove(mv.To + 1))
+    }
+
+    p.put(mv.From, mv.Piece)
+
+    if mv.Flags & FlagCapture != 0 {
+        p.put(capturedSquare(mv), mv.Captured)
+    }
+
+    p.turn = u.turn
+    p.castlingRights = u.castlingRights
+    p.enPassant = u.enPassant
+    p.halfmoveClock = u.halfmoveClock
+    p.fullmoveNumber = u.fullmoveNumber
+}
+
+// the square the captured piece is on, this is not the square moved to when capturing en passant
+func capturedSquare(mv Move) int {
+    if mv.Flags & FlagEnPassant != 0 {
+        return mv.From - mv.From % rowsAndColums + mv.To % rowsAndColums
+    }
+    return mv.To
+}
+
+// removes the castling rights lost by moving from or to the square
+// moving the king loses both rights, moving or capturing a rook loses the right on its side
+func (p *Position) updateCastlingRights(sq int) {
+    switch sq {
+        case squareE1:
+            p.castlingRights.whiteKingSide = false
+            p.castlingRights.whiteQueenSide = false
+        case squareE8:
+            p.castlingRights.blackKingSide = false
+            p.castlingRights.blackQueenSide = false
+        case squareH1:
+            p.castlingRights.whiteKingSide = false
+        case squareA1:
+            p.castlingRights.whiteQueenSide = false
+        case squareH8:
+            p.castlingRights.blackKingSide = false
+        case squareA8:
+            p.castlingRights.blackQueenSide = false
+    }
+}
+
+// returns the king side and queen side castling rights of a color
+func (c castlingRights) get(color PieceColor) (kingSide bool, queenSide bool) {
+    if color == PieceColorWhite {
+        return c.whiteKingSide, c.whiteQueenSide
+    }
+    return c.blackKingSide, c.blackQueenSide
+}
+
+// checks if neither player has enough pieces left to checkmate
+// this is the case for king against king, a king and a single bishop or knight against a king,
+// and when all the remaining bishops are on squares of the same color
+func (p *Position) InsufficientMaterial() bool {
+    for _, pieces := range p.pieces {
+        // pawns, rooks and queens can always mate
+        if pieces[PieceKindPawn] | pieces[PieceKindRook] | pieces[PieceKindQueen] != 0 {
+            return false
+        }
+    }
+
+    knights := p.pieces[PieceColorWhite][PieceKindKnight] | p.pieces[PieceColorBlack][PieceKindKnight]
+    bishops := p.pieces[PieceColorWhite][PieceKindBishop] | p.pieces[PieceColorBlack][PieceKindBishop]
+
+    if knights.count() + bishops.count() <= 1 {
+        return true
+    }
+
+    return knights == 0 && (bishops & lightSquares == 0 || bishops &^ lightSquares == 0)
+}
+
+// creates a key for the position, two positions are the same if they have the same board, player to move,
+// castling rights and en passant captures
+func (p *Position) key() string {
+    key := ""
+
+    for _, piece := range p.squares {
+        key += strconv.Itoa(int(piece.Color)) + strconv.Itoa(int(piece.Kind))
+    }
+
+    key += " " + strconv.Itoa(int(p.turn))
+    key += " " + fmt.Sprint(p.castlingRights)
+
+    // the en passant square only matters if a pawn can actually capture on it
+    if p.enPassant != -1 {
+        for _, mv := range p.LegalMoves() {
+            if mv.Flags & FlagEnPassant != 0 {
+                key += " " + strconv.Itoa(p.enPassant)
+                break
+            }
+        }
+    }
+
+    return key
+}
+
+// formats the move as the squares it moves between, followed by the promotion piece, for example e7e8q
+func (mv Move) String() string {
+    s := SquareName(mv.From) + SquareName(mv.To)
+    if mv.Promotion != PieceKindNone {
+        s += string(pieceLetters[mv.Promotion] + 'a' - 'A')
+    }
+    return s
+}
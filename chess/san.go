@@ -0,0 +1,232 @@
+package chess
+
+import (
+    "errors"
+    "strings"
+)
+
+// writes the move in Standard Algebraic Notation, for example Nf3, exd5, O-O or e8=Q+
+// the move has to be one of the legal moves of the position
+func (p *Position) SAN(mv Move) string {
+    return p.san(mv, p.LegalMoves())
+}
+
+func (p *Position) san(mv Move, legal []Move) string {
+    s := ""
+
+    switch {
+        case mv.Flags & FlagKingSideCastle != 0:
+            s = "O-O"
+
+        case mv.Flags & FlagQueenSideCastle != 0:
+            s = "O-O-O"
+
+        case mv.Piece.Kind == PieceKindPawn:
+            if mv.Flags & FlagCapture != 0 {
+                s = SquareName(mv.From)[:1] + "x"
+            }
+            s += SquareName(mv.To)
+
+            if mv.Flags & FlagPromotion != 0 {
+                s += "=" + mv.Promotion.Letter()
+            }
+
+        default:
+            s = mv.Piece.Kind.Letter() + p.disambiguation(mv, legal)
+            if mv.Flags & FlagCapture != 0 {
+                s += "x"
+            }
+            s += SquareName(mv.To)
+    }
+
+    u := p.MakeMove(mv)
+    if p.InCheck(p.turn) {
+        if len(p.LegalMoves()) == 0 {
+            s += "#"
+        } else {
+            s += "+"
+        }
+    }
+    p.UnmakeMove(u)
+
+    return s
+}
+
+// the column, row or square of the moving piece needed to tell the move apart
+// from moves of identical pieces to the same square
+func (p *Position) disambiguation(mv Move, legal []Move) string {
+    from := SquareName(mv.From)
+    sameColumn, sameRow, others := false, false, false
+
+    for _, other := range legal {
+        if other.To != mv.To || other.From == mv.From || other.Piece != mv.Piece {
+            continue
+        }
+
+        others = true
+        if other.From % rowsAndColums == mv.From % rowsAndColums {
+            sameColumn = true
+        }
+        if other.From / rowsAndColums == mv.From / rowsAndColums {
+            sameRow = true
+        }
+    }
+
+    switch {
+        case !others:
+            return ""
+        case !sameColumn:
+            return from[:1]
+        case !sameRow:
+            return from[1:]
+    }
+    return from
+}
+
+// reads a move in Standard Algebraic Notation and finds it among the legal moves of the position
+// check and annotation marks are ignored, and a missing capture mark or extra disambiguation is accepted
+func (p *Position) ParseSAN(san string) (error, Move) {
+    s := strings.TrimRight(san, "+#!?")
+
+    // castling is written with the letter O, some programs use zeros
+    switch strings.ReplaceAll(s, "0", "O") {
+        case "O-O":
+            return p.findMove(san, PieceKindKing, -1, -1, castlingTarget(p.turn, 2), PieceKindNone, FlagKingSideCastle)
+        case "O-O-O":
+            return p.findMove(san, PieceKindKing, -1, -1, castlingTarget(p.turn, -2), PieceKindNone, FlagQueenSideCastle)
+    }
+
+    kind := PieceKindPawn
+    if len(s) > 0 && s[0] >= 'A' && s[0] <= 'Z' {
+        var ok bool
+        kind, ok = pieceKindOf(s[0])
+        if !ok || kind == PieceKindPawn {
+            return errors.New("unknown piece in move " + san), Move{}
+        }
+        s = s[1:]
+    }
+
+    promotion := PieceKindNone
+    if i := strings.IndexByte(s, '='); i != -1 {
+        if i != len(s) - 2 {
+            return errors.New("invalid promotion in move " + san), Move{}
+        }
+        var ok bool
+        promotion, ok = pieceKindOf(s[i + 1])
+        if !ok || s[i + 1] < 'A' || s[i + 1] > 'Z' {
+            return errors.New("invalid promotion in move " + san), Move{}
+        }
+        s = s[:i]
+    }
+
+    if len(s) < 2 {
+        return errors.New("invalid move " + san), Move{}
+    }
+
+    err, to := ParseSquare(s[len(s) - 2:])
+    if err != nil {
+        return errors.New("invalid move " + san), Move{}
+    }
+    s = strings.TrimSuffix(s[:len(s) - 2], "x")
+
+    // what is left is the column, row or square of the moving piece
+    column, row := -1, -1
+    for i := 0; i < len(s); i++ {
+        switch {
+            case s[i] >= 'a' && s[i] <= 'h' && column == -1 && row == -1:
+                column = int(s[i] - 'a')
+            case s[i] >= '1' && s[i] <= '8' && row == -1:
+                row = rowsAndColums - int(s[i] - '0')
+            default:
+                return errors.New("invalid move " + san), Move{}
+        }
+    }
+
+    return p.findMove(san, kind, column, row, to, promotion, 0)
+}
+
+// finds the one legal move of a piece of the kind to the square, column and row are -1 if any is allowed
+// castling is the castling flag the move must have, 0 for moves that are not castling
+func (p *Position) findMove(san string, kind PieceKind, column int, row int, to int, promotion PieceKind, castling MoveFlags) (error, Move) {
+    found := []Move{}
+
+    for _, mv := range p.LegalMoves() {
+        c := coordinateOf(mv.From)
+        if mv.To != to || mv.Piece.Kind != kind || mv.Promotion != promotion ||
+        mv.Flags & (FlagKingSideCastle | FlagQueenSideCastle) != castling ||
+        column != -1 && c.x != column || row != -1 && c.y != row {
+            continue
+        }
+        found = append(found, mv)
+    }
+
+    switch len(found) {
+        case 0:
+            if kind == PieceKindPawn && promotion == PieceKindNone && (to < rowsAndColums || to >= 56) {
+                return errors.New("illegal move " + san + ", the promotion piece is missing"), Move{}
+            }
+            return errors.New("illegal move " + san), Move{}
+        case 1:
+            return nil, found[0]
+    }
+
+    return errors.New("ambiguous move " + san + ", it could be " + p.SAN(found[0]) + " or " + p.SAN(found[1])), Move{}
+}
+
+// the square the king of the color castles to, two squares to the right or left of its starting square
+func castlingTarget(color PieceColor, step int) int {
+    if color == PieceColorWhite {
+        return squareE1 + step
+    }
+    return squareE8 + step
+}
+
+// reads a move in Standard Algebraic Notation like Nf3, or in long algebraic notation like g1f3
+func (p *Position) ParseMove(s string) (error, Move) {
+    s = strings.TrimSpace(s)
+
+    // SAN never has two squares without a capture mark between them
+    if len(s) >= 4 && isSquareName(s[:2]) && isSquareName(s[2:4]) {
+        return p.ParseLongAlgebraic(s)
+    }
+    return p.ParseSAN(s)
+}
+
+// reads a move in long algebraic notation as written by Move.String, for example g1f3 or e7e8q
+func (p *Position) ParseLongAlgebraic(s string) (error, Move) {
+    if len(s) != 4 && len(s) != 5 || !isSquareName(s[:2]) || !isSquareName(s[2:4]) {
+        return errors.New("invalid move " + s), Move{}
+    }
+
+    _, from := ParseSquare(s[:2])
+    _, to := ParseSquare(s[2:4])
+
+    promotion := PieceKindNone
+    if len(s) == 5 {
+        var ok bool
+        promotion, ok = pieceKindOf(s[4])
+        if !ok || promotion == PieceKindPawn || promotion == PieceKindKing {
+            return errors.New("invalid promotion in move " + s), Move{}
+        }
+    }
+
+    missingPromotion := false
+    for _, mv := range p.LegalMoves() {
+        if mv.From != from || mv.To != to {
+            continue
+        }
+        if mv.Promotion == promotion {
+            return nil, mv
+        }
+        missingPromotion = promotion == PieceKindNone
+    }
+
+    if missingPromotion {
+        return errors.New("illegal move " + s + ", the promotion piece is missing"), Move{}
+    }
+    return errors.New("illegal move " + s), Move{}
+}
+
+func isSquareName(s string) bool {
+    return len(s) == 2 && s[0] >= 'a' && s[0] <= 'h' && s[1] >= '1' && s[1] <= '8'
+}
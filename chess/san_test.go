@@ -0,0 +1,158 @@
+package chess
+
+import "testing"
+
+// positions with castling, en passant, promotions and checks to read and write every legal move in
+var notationPositions = []string{
+    StartingFEN,
+    "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+    "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+    "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+    "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+}
+
+func TestSAN(t *testing.T) {
+    moves := []struct {
+        fen string
+        // the move in long algebraic notation
+        move string
+        san string
+    }{
+        {StartingFEN, "g1f3", "Nf3"},
+        {"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", "exd5"},
+        {"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+        {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", "O-O"},
+        {"r3k3/8/8/8/8/8/8/4K3 b q - 0 1", "e8c8", "O-O-O"},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", "a8=Q+"},
+        {"6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", "Ra8#"},
+        {"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1d1", "Rad1"},
+        {"4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", "a1a3", "R1a3"},
+        {"4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", "a1b2", "Qa1b2"},
+    }
+
+    for _, m := range moves {
+        _, p := ParseFEN(m.fen)
+
+        found := false
+        for _, mv := range p.LegalMoves() {
+            if mv.String() != m.move {
+                continue
+            }
+            found = true
+            if san := p.SAN(mv); san != m.san {
+                t.Errorf("%s: %s was written as %s, expected %s", m.fen, m.move, san, m.san)
+            }
+        }
+        if !found {
+            t.Errorf("%s: %s is not a legal move", m.fen, m.move)
+        }
+    }
+}
+
+// every legal move written in SAN or long algebraic notation is read back as the same move
+func TestSANRoundTrip(t *testing.T) {
+    for _, fen := range notationPositions {
+        _, p := ParseFEN(fen)
+        checkNotationRoundTrip(t, &p, 2)
+    }
+}
+
+func checkNotationRoundTrip(t *testing.T, p *Position, depth int) {
+    for _, mv := range p.LegalMoves() {
+        san := p.SAN(mv)
+
+        err, parsed := p.ParseSAN(san)
+        if err != nil || parsed != mv {
+            t.Errorf("%s: %s was read as %v, %v", p.FEN(), san, parsed, err)
+        }
+
+        err, parsed = p.ParseMove(mv.String())
+        if err != nil || parsed != mv {
+            t.Errorf("%s: %s was read as %v, %v", p.FEN(), mv, parsed, err)
+        }
+
+        if depth > 1 {
+            u := p.MakeMove(mv)
+            checkNotationRoundTrip(t, p, depth - 1)
+            p.UnmakeMove(u)
+        }
+    }
+}
+
+func TestParseSAN(t *testing.T) {
+    moves := []struct {
+        fen string
+        san string
+        // the move in long algebraic notation, empty if the SAN should not be accepted
+        move string
+    }{
+        {StartingFEN, "Nf3", "g1f3"},
+        {StartingFEN, "Ngf3", "g1f3"},
+        {StartingFEN, "e4", "e2e4"},
+        {StartingFEN, "e4!?", "e2e4"},
+        {StartingFEN, "Ke2", ""},
+        {StartingFEN, "e5", ""},
+        {StartingFEN, "Xe4", ""},
+        {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "O-O", "e1g1"},
+        {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "0-0", "e1g1"},
+        {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "Kg1", ""},
+        {"r3k3/8/8/8/8/8/8/4K3 b q - 0 1", "O-O-O", "e8c8"},
+        {"r3k3/8/8/8/8/8/8/4K3 b q - 0 1", "Kc8", ""},
+        // the king is next to the square it would castle to
+        {"4k3/8/8/8/8/8/8/5K1R w - - 0 1", "O-O", ""},
+        {"4k3/8/8/8/8/8/8/5K1R w - - 0 1", "Kg1", "f1g1"},
+        {"3k3r/8/8/8/8/8/8/4K3 b - - 0 1", "O-O-O", ""},
+        {"3k3r/8/8/8/8/8/8/4K3 b - - 0 1", "Kc8", "d8c8"},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=Q", "a7a8q"},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8", ""},
+        {"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rd1", ""},
+        {"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rad1", "a1d1"},
+    }
+
+    for _, m := range moves {
+        _, p := ParseFEN(m.fen)
+        err, mv := p.ParseSAN(m.san)
+
+        switch {
+            case m.move == "" && err == nil:
+                t.Errorf("%s: %s was accepted as %v", m.fen, m.san, mv)
+            case m.move != "" && err != nil:
+                t.Errorf("%s: %s: %v", m.fen, m.san, err)
+            case m.move != "" && mv.String() != m.move:
+                t.Errorf("%s: %s was read as %v, expected %s", m.fen, m.san, mv, m.move)
+        }
+    }
+}
+
+func TestParseMove(t *testing.T) {
+    moves := []struct {
+        fen string
+        input string
+        // the move in long algebraic notation, empty if the input should not be accepted
+        move string
+    }{
+        {StartingFEN, "g1f3", "g1f3"},
+        {StartingFEN, " Nf3 ", "g1f3"},
+        {StartingFEN, "e2e5", ""},
+        {StartingFEN, "g1f3q", ""},
+        {StartingFEN, "g1f3x", ""},
+        {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", "e1g1"},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8n", "a7a8n"},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8", ""},
+        {"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8k", ""},
+    }
+
+    for _, m := range moves {
+        _, p := ParseFEN(m.fen)
+        err, mv := p.ParseMove(m.input)
+
+        switch {
+            case m.move == "" && err == nil:
+                t.Errorf("%s: %q was accepted as %v", m.fen, m.input, mv)
+            case m.move != "" && err != nil:
+                t.Errorf("%s: %q: %v", m.fen, m.input, err)
+            case m.move != "" && mv.String() != m.move:
+                t.Errorf("%s: %q was read as %v, expected %s", m.fen, m.input, mv, m.move)
+        }
+    }
+}
@@ -0,0 +1,42 @@
+package chess
+
+import (
+    "errors"
+    "strconv"
+)
+
+// the square in column x and row y, row 0 is the eighth rank
+func SquareAt(x int, y int) int {
+    return squareOf(coordinate{x, y})
+}
+
+// the column and row of the square, row 0 is the eighth rank
+func SquareXY(sq int) (int, int) {
+    c := coordinateOf(sq)
+    return c.x, c.y
+}
+
+// the name of the square in algebraic notation, for example e4
+func SquareName(sq int) string {
+    if sq < 0 || sq >= 64 {
+        return "-"
+    }
+
+    c := coordinateOf(sq)
+    return string(rune('a' + c.x)) + strconv.Itoa(rowsAndColums - c.y)
+}
+
+// reads a square in algebraic notation, for example e4
+func ParseSquare(s string) (error, int) {
+    if len(s) != 2 {
+        return errors.New("invalid square " + strconv.Quote(s) + ", a square is a file a-h followed by a rank 1-8"), -1
+    }
+    if s[0] < 'a' || s[0] > 'h' {
+        return errors.New("invalid square " + s + ", the file must be a letter from a to h"), -1
+    }
+    if s[1] < '1' || s[1] > '8' {
+        return errors.New("invalid square " + s + ", the rank must be a number from 1 to 8"), -1
+    }
+
+    return nil, squareOf(coordinate{int(s[0] - 'a'), rowsAndColums - int(s[1] - '0')})
+}
@@ -0,0 +1,244 @@
+package chess
+
+import (
+    "errors"
+    "strconv"
+)
+
+// the symbols of the most used numeric annotation glyphs, other glyphs are written as $n
+var nagSymbols = map[int]string{
+    1: "!",
+    2: "?",
+    3: "!!",
+    4: "??",
+    5: "!?",
+    6: "?!",
+    10: "=",
+    14: "+=",
+    15: "=+",
+    16: "+/-",
+    17: "-/+",
+    18: "+-",
+    19: "-+",
+}
+
+// a move in the move tree of a game
+// the first child continues the line the move is in, the other children are variations of it
+type MoveNode struct {
+    Move Move
+    // the move in standard algebraic notation
+    SAN string
+    // a comment on the move, on the root it comes before the first move
+    Comment string
+    // a comment written before the move, like the comment at the start of a variation
+    CommentBefore string
+    // numeric annotation glyphs like 1 for a good move or 14 for a slight advantage for white
+    NAGs []int
+    Children []*MoveNode
+    parent *MoveNode
+}
+
+// the move before this one, nil for the root of the tree
+func (n *MoveNode) Parent() *MoveNode {
+    return n.parent
+}
+
+// adds the annotation glyph, or removes it if the move already has it
+// a move has at most one glyph judging the move (1 to 9) and one judging the position (10 to 19)
+func (n *MoveNode) ToggleNAG(nag int) {
+    nags := []int{}
+    found := false
+
+    for _, other := range n.NAGs {
+        switch {
+            case other == nag:
+                found = true
+            case nagGroup(other) != 0 && nagGroup(other) == nagGroup(nag):
+                // replaced by the new glyph
+            default:
+                nags = append(nags, other)
+        }
+    }
+
+    if !found {
+        nags = append(nags, nag)
+    }
+    n.NAGs = nags
+}
+
+// the move in SAN followed by its annotation glyphs, like Nf3!? +=
+func (n *MoveNode) Annotated() string {
+    s := n.SAN
+
+    for _, nag := range n.NAGs {
+        if nagGroup(nag) == 1 {
+            s += NAGSymbol(nag)
+        } else {
+            s += " " + NAGSymbol(nag)
+        }
+    }
+
+    return s
+}
+
+// the symbol of the annotation glyph, or $n if it has no symbol
+func NAGSymbol(nag int) string {
+    if symbol, ok := nagSymbols[nag]; ok {
+        return symbol
+    }
+    return "$" + strconv.Itoa(nag)
+}
+
+// 1 for glyphs judging the move, 2 for glyphs judging the position and 0 for the others
+func nagGroup(nag int) int {
+    switch {
+        case nag >= 1 && nag <= 9:
+            return 1
+        case nag >= 10 && nag <= 19:
+            return 2
+    }
+    return 0
+}
+
+// the child played with the move, nil if the move was not played from here
+func (n *MoveNode) child(mv Move) *MoveNode {
+    for _, c := range n.Children {
+        if c.Move.From == mv.From && c.Move.To == mv.To && c.Move.Promotion == mv.Promotion {
+            return c
+        }
+    }
+    return nil
+}
+
+// the index of the node among the children of its parent, 0 if it continues the line of its parent
+func (n *MoveNode) index() int {
+    for i, c := range n.parent.Children {
+        if c == n {
+            return i
+        }
+    }
+    return -1
+}
+
+// the first move of the variation the node is in, nil if the node is on the main line
+func (n *MoveNode) variationStart() *MoveNode {
+    for ; n.parent != nil; n = n.parent {
+        if n.index() != 0 {
+            return n
+        }
+    }
+    return nil
+}
+
+// the root of the move tree, it stands for the starting position and has no move
+func (g *Game) Root() *MoveNode {
+    return g.root
+}
+
+// the node of the last move played, the root before the first move
+func (g *Game) Current() *MoveNode {
+    return g.current
+}
+
+// checks if the last move played is on the main line, which is also the case before the first move
+func (g *Game) OnMainLine() bool {
+    return g.current.variationStart() == nil
+}
+
+// plays or takes back moves until the last move played is the node
+func (g *Game) GoTo(node *MoveNode) error {
+    path := []*MoveNode{}
+    onPath := map[*MoveNode]bool{}
+    for n := node; n != nil; n = n.parent {
+        path = append(path, n)
+        onPath[n] = true
+    }
+
+    if path[len(path) - 1] != g.root {
+        return errors.New("the move is not in this game")
+    }
+
+    for !onPath[g.current] {
+        g.UndoMove()
+    }
+
+    // the path runs from the node back to the root, so it is played from the end
+    for i := len(path) - 1; i >= 0; i-- {
+        if path[i].parent != g.current {
+            continue
+        }
+        if err, _ := g.MakeMove(path[i].Move); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// moves the variation the last move played is in one place up, the first variation becomes the main line
+func (g *Game) PromoteVariation() error {
+    start := g.current.variationStart()
+    if start == nil {
+        return errors.New("the move is already on the main line")
+    }
+
+    siblings := start.parent.Children
+    i := start.index()
+    siblings[i - 1], siblings[i] = siblings[i], siblings[i - 1]
+
+    return nil
+}
+
+// removes the variation the last move played is in and goes back to the move it started from
+// on the main line the last move played and the moves after it are removed,
+// the first variation of the move then continues the main line
+func (g *Game) DeleteVariation() error {
+    start := g.current.variationStart()
+    if start == nil {
+        start = g.current
+    }
+    if start == g.root {
+        return errors.New("there are no moves to delete")
+    }
+
+    if err := g.GoTo(start.parent); err != nil {
+        return err
+    }
+
+    siblings := start.parent.Children
+    i := start.index()
+    start.parent.Children = append(siblings[:i:i], siblings[i + 1:]...)
+
+    return nil
+}
+
+// the moves of the main line, from the first move to the last
+func (g *Game) MainLine() []*MoveNode {
+    line := []*MoveNode{}
+    for n := g.root; len(n.Children) != 0; n = n.Children[0] {
+        line = append(line, n.Children[0])
+    }
+    return line
+}
+
+// a copy of the game with its own move tree, at the same move as the game
+func (g *Game) Clone() Game {
+    clone := NewGame(g.start)
+    clone.root = cloneNode(g.root, nil)
+    clone.current = clone.root
+
+    // the moves played are found in the copied tree by their moves
+    for _, mv := range g.Moves() {
+        clone.MakeMove(mv)
+    }
+
+    return clone
+}
+
+func cloneNode(n *MoveNode, parent *MoveNode) *MoveNode {
+    clone := &MoveNode{Move: n.Move, SAN: n.SAN, Comment: n.Comment, CommentBefore: n.CommentBefore, NAGs: append([]int{}, n.NAGs...), parent: parent}
+    for _, c := range n.Children {
+        clone.Children = append(clone.Children, cloneNode(c, clone))
+    }
+    return clone
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+    "os"
+    "strings"
+
+    "github.com/aymanbagabas/go-osc52/v2"
+)
+
+// copies the text to the system clipboard with an OSC 52 escape sequence
+// the terminal does the copying, so this also works over SSH
+func copyToClipboard(text string) error {
+    seq := osc52.New(text)
+
+    // tmux and screen only pass the sequence on to the terminal when it is wrapped for them
+    if os.Getenv("TMUX") != "" {
+        seq = seq.Tmux()
+    } else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+        seq = seq.Screen()
+    }
+
+    _, err := seq.WriteTo(os.Stderr)
+    return err
+}
+
+// copies the position on the board as FEN
+func (m *model) copyFEN() {
+    m.copy("the position as FEN", m.game.Position().FEN())
+}
+
+// copies the game as PGN
+func (m *model) copyPGN() {
+    m.copy("the game as PGN", m.pgn())
+}
+
+func (m *model) copy(what string, text string) {
+    if err := copyToClipboard(text); err != nil {
+        m.message = "could not copy " + what + ": " + err.Error()
+        logToFile(m.message)
+        return
+    }
+
+    m.message = "copied " + what + " to the clipboard"
+    logToFile(m.message + ": " + text)
+}
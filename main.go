@@ -3,26 +3,87 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
     "strconv"
+    "time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+    "tui-chess/chess"
 )
 
 type model struct {
     cursor coordinate
     selected coordinate
-    board [8][8]piece
-    capturedP1 []piece
-    capturedP2 []piece
-    moveLog []string
+    game chess.Game
+    // the legal moves of every piece that can move
+    possibleMoves []chess.Move
+    capturedP1 []chess.Piece
+    capturedP2 []chess.Piece
     player1 player
     player2 player
 
     // player turn can be 1 for player1, 2 for player2 or 0 if freemoving
     playerTurn int
+
+    // the square the selected pawn is being promoted on, {-1, -1} if no pawn is being promoted
+    promotion coordinate
+    // the index of the piece currently chosen in the promotion picker
+    promotionChoice int
+
+    // the rule a draw can be claimed by, empty if the player whose turn it is can not claim a draw
+    drawClaim string
+
+    // the size of the terminal, 0 until bubbletea has sent it
+    width int
+    height int
+
+    // the mode the game was started with, used when starting a new game
+    mode string
+    // when the game was started, used for the date of the game when saving it
+    started time.Time
+    // the file the game is saved to as PGN
+    pgnFile string
+    // true while a move is typed in the move prompt
+    prompting bool
+    // the text typed in the move prompt, the comment editor or the glyph picker
+    input string
+    // why the typed move can not be played, shown below the prompt
+    inputError string
+
+    // true while the comment of the last move is edited, the comment is kept in input
+    editingComment bool
+    // true while annotation glyphs are chosen for the last move
+    choosingNAG bool
+    // the index in nagOptions of the glyph chosen in the glyph picker
+    nagChoice int
+
+    // the position given with --fen, new games start from it as well
+    startFEN string
+
+    // a message shown below the board, for example after saving the game
+    message string
+
+    // the games opened from a PGN file
+    pgnGames []chess.PGNGame
+    // the PGN file the games were opened from, saving never writes to it
+    pgnOpened string
+    // the tags of the game opened from a PGN file, they are written again when the game is saved
+    pgnTags []chess.Tag
+    // the arrow keys step through the moves of the game when replaying a game from a PGN file
+    replaying bool
+    // true while the game picker is shown
+    choosingGame bool
+    // the index of the game currently chosen in the game picker
+    gameChoice int
+
+    // result of the game, "1-0", "0-1" or "1/2-1/2", empty while the game is still going
+    result string
+    // why the game ended, for example checkmate or stalemate
+    resultReason string
 }
 
 type coordinate struct {
@@ -30,25 +91,11 @@ type coordinate struct {
     y int
 }
 
-type piece struct {
-    unicode string
-    pieceColor pieceColor
-    possibleMoves []coordinate
-}
-
 type player struct {
     name string
-    possibleMoves []coordinate
     checked bool
 }
 
-type pieceColor string
-
-const (
-    pieceColorBlack pieceColor = "black"
-    pieceColorWhite pieceColor = "white"
-)
-
 const rowsAndColums = 8
 const debugging = 1
 
@@ -69,6 +116,7 @@ var selectedColor = Red
 var possibleMoveColor = Yellow
 var boardColor = White
 var pieceMarkupColor = White
+var checkColor = Purple
 
 func main(){
 
@@ -85,10 +133,33 @@ func main(){
     var model model
 
     args := os.Args[1:]
-    if len(args) == 0 {
+
+    if len(args) != 0 {
+        switch args[0] {
+            case "perft", "divide":
+                if err := runPerft(args[0], args[1:]); err != nil {
+                    fmt.Println(err)
+                    os.Exit(1)
+                }
+                return
+        }
+    }
+
+    options := flag.NewFlagSet("tui-chess", flag.ExitOnError)
+    pgnOut := options.String("pgn-out", "", "save the game as PGN to `file` when quitting, s saves to it as well")
+    pgnIn := options.String("pgn", "", "open the games in the PGN `file` and step through them with the arrow keys")
+    fen := options.String("fen", "", "start the game from the position in `FEN`")
+    options.Parse(args)
+
+    if *fen != "" && (*pgnIn != "" || options.NArg() != 0) {
+        fmt.Println("--fen can not be used together with --pgn or a board")
+        os.Exit(1)
+    }
+
+    if options.NArg() == 0 {
         err, model = initialModel("default")
     } else {
-        err, model = initialModel(args[0])
+        err, model = initialModel(options.Arg(0))
     }
 
 
@@ -97,20 +168,64 @@ func main(){
         os.Exit(1)
     }
 
+    if *fen != "" {
+        err, p := chess.ParseFEN(*fen)
+        if err == nil {
+            err = p.Validate()
+        }
+        if err != nil {
+            fmt.Println("invalid FEN: " + err.Error())
+            os.Exit(1)
+        }
+        model.startFEN = *fen
+        model.setPosition(p)
+    }
+
+    // saving would replace every game in the file with the one game on the board
+    if *pgnIn != "" && *pgnOut != "" && sameFile(*pgnIn, *pgnOut) {
+        fmt.Println("--pgn-out can not be the file opened with --pgn")
+        os.Exit(1)
+    }
+
+    if *pgnIn != "" {
+        content, err := os.ReadFile(*pgnIn)
+        if err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
+
+        err, games := chess.ParsePGN(string(content))
+        if err != nil {
+            fmt.Println(*pgnIn + ": " + err.Error())
+            os.Exit(1)
+        }
+        model.openPGN(games)
+        model.pgnOpened = *pgnIn
+    }
+
+    if *pgnOut != "" {
+        model.pgnFile = *pgnOut
+    }
+
     p := tea.NewProgram(model)
 
-    if _, err := p.Run(); err != nil {
+    final, err := p.Run()
+    if err != nil {
         fmt.Printf("Alas, there's been an error: %v", err)
         os.Exit(1)
     }
 
+    if *pgnOut != "" {
+        savePGNOnQuit(final)
+    }
+
 }
 
 func initialModel(mode string) (error, model) {
     m := model {
         cursor: coordinate{4, 4},
         selected: coordinate{-1, -1},
-        board: boardDefault,
+        promotion: coordinate{-1, -1},
         player1: player{
             name: "player 1",
             checked: false,
@@ -119,55 +234,46 @@ func initialModel(mode string) (error, model) {
             name: "player 2",
             checked: false,
         },
+        mode: mode,
+        started: time.Now(),
+        pgnFile: "game.pgn",
     }
+    board := "default"
+
     switch mode{
         case "default":
             m.cursor = coordinate{4, 7}
             m.playerTurn = 1
-            m.calculateMoves()
-            return nil, m
         case "freeplay":
             m.cursor = coordinate{4, 7}
-            m.calculateMoves()
-            return nil, m
-
-        case "testRook":
-            m.board = boardTestRook
-            m.calculateMoves()
-            return nil, m
-
-        case "testPawn":
-            m.board = boardTestPawn
-            m.calculateMoves()
-            return nil, m
-
-        case "testBishop":
-            m.board = boardTestBishop
-            m.calculateMoves()
-            return nil, m
-
-        case "testKnight":
-            m.board = boardTestKnight
-            m.calculateMoves()
-            return nil, m
-        
-        case "testQueen":
-            m.board = boardTestQueen
-            m.calculateMoves()
-        return nil, m
-
-        case "testKing":
-            m.board = boardTestKing
-            m.calculateMoves()
-        return nil, m
-
-        case "testEmpty":
-            m.board = boardTestEmpty
-            return nil, m
 
     default:
-        return errors.New("unrecognized board"), model{}
+        board = mode
+    }
+
+    err, p := getBoard(board)
+    if err != nil {
+        return err, model{}
+    }
+
+    m.setPosition(p)
+
+    return nil, m
+}
+
+// starts the game from the position, outside of freeplay the side to move in the position moves first
+func (m *model) setPosition(p chess.Position) {
+    if m.playerTurn != 0 {
+        m.playerTurn = 1
+        if p.Turn() == chess.PieceColorBlack {
+            m.playerTurn = 2
+        }
     }
+
+    m.game = chess.NewGame(p)
+    m.calculateMoves()
+    m.checkForChecks()
+    m.checkForGameOver()
 }
 
 func (m model) Init() tea.Cmd {
@@ -180,9 +286,69 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
     switch msg := msg.(type) {
 
+    // the terminal was resized
+    case tea.WindowSizeMsg:
+        m.width = msg.Width
+        m.height = msg.Height
+
     // Is it a key press?
     case tea.KeyMsg:
 
+        // messages are shown until the next key press
+        m.message = ""
+
+        if m.choosingGame {
+            return m.updateGamePicker(msg)
+        }
+
+        if m.prompting {
+            return m.updatePrompt(msg)
+        }
+
+        if m.editingComment {
+            return m.updateCommentEditor(msg)
+        }
+
+        if m.choosingNAG {
+            return m.updateNAGPicker(msg)
+        }
+
+        // the arrow keys step through the game, even when it is over
+        if m.replaying && m.promotion.x == -1 {
+            switch msg.String() {
+            case "left":
+                m.undoMove()
+                return m, nil
+            case "right":
+                m.redoMove()
+                return m, nil
+            case "home":
+                m.game.GoTo(m.game.Root())
+                m.followGame()
+                return m, nil
+            case "end":
+                for len(m.game.Current().Children) != 0 {
+                    m.redoMove()
+                }
+                return m, nil
+            case "g":
+                if len(m.pgnGames) > 1 {
+                    m.choosingGame = true
+                }
+                return m, nil
+            }
+        }
+
+        // the board is locked when the game is over
+        if m.result != "" {
+            return m.updateGameOver(msg)
+        }
+
+        // the board is locked while the player chooses a piece to promote to
+        if m.promotion.x != -1 {
+            return m.updatePromotion(msg)
+        }
+
         // Cool, what was the actual key pressed?
         switch msg.String() {
 
@@ -218,25 +384,181 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
         /* select piece */
         case "enter", " ":
             m.selectSquare()
+
+        /* take back the last move */
+        case "u":
+            m.undoMove()
+
+        /* play the move that was taken back again */
+        case "r":
+            m.redoMove()
+
+        /* promote the variation */
+        case "+":
+            m.promoteVariation()
+
+        /* delete the variation */
+        case "x":
+            m.deleteVariation()
+
+        /* save the game */
+        case "s":
+            m.savePGN()
+
+        /* copy the position as FEN */
+        case "f":
+            m.copyFEN()
+
+        /* copy the game as PGN */
+        case "p":
+            m.copyPGN()
+
+        /* comment on the last move */
+        case "c":
+            m.editComment()
+
+        /* annotate the last move */
+        case "a":
+            m.chooseNAG()
+
+        /* type a move */
+        case "/":
+            m.prompting = true
+            m.selected = coordinate{-1, -1}
+
+        /* claim a draw */
+        case "d":
+            if m.drawClaim != "" {
+                m.result = chess.ResultDraw
+                m.resultReason = m.drawClaim
+                logToFile("draw claimed: " + m.drawClaim)
+            }
         }
 
     }
     return m, nil
 }
 
+// handles key presses on the game over screen
+func (m model) updateGameOver(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    switch msg.String() {
+
+    /* quit program */
+    case "ctrl+c", "ctrl+d", "q":
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* take back the last move and continue the game */
+    case "u":
+        m.undoMove()
+
+    /* save the game */
+    case "s":
+        m.savePGN()
+
+    /* copy the position as FEN */
+    case "f":
+        m.copyFEN()
+
+    /* copy the game as PGN */
+    case "p":
+        m.copyPGN()
+
+    /* comment on the last move */
+    case "c":
+        m.editComment()
+
+    /* annotate the last move */
+    case "a":
+        m.chooseNAG()
+
+    /* start a new game in the same mode, or choose another game from the PGN file */
+    case "n":
+        err, newModel := initialModel(m.mode)
+        if err != nil {
+            return m, nil
+        }
+        newModel.player1.name = m.player1.name
+        newModel.player2.name = m.player2.name
+        newModel.width = m.width
+        newModel.height = m.height
+        newModel.pgnFile = m.pgnFile
+        newModel.pgnOpened = m.pgnOpened
+        if m.startFEN != "" {
+            _, p := chess.ParseFEN(m.startFEN)
+            newModel.startFEN = m.startFEN
+            newModel.setPosition(p)
+        }
+        // games opened from a PGN file go back to the game picker, or reload the only game
+        if m.pgnGames != nil {
+            newModel.openPGN(m.pgnGames)
+        }
+        return newModel, nil
+    }
+
+    return m, nil
+}
+
+// handles key presses in the promotion picker
+func (m model) updatePromotion(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    options := getPromotionOptions(m.pieceAt(m.selected).Color)
+
+    switch msg.String() {
+
+    /* quit program, q is used for choosing the queen */
+    case "ctrl+c", "ctrl+d":
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* move choice left */
+    case "h", "left":
+        if m.promotionChoice != 0 {
+            m.promotionChoice --
+        }
+
+    /* move choice right */
+    case "l", "right":
+        if m.promotionChoice < len(options) - 1 {
+            m.promotionChoice ++
+        }
+
+    /* promote to the chosen piece */
+    case "enter", " ":
+        m.promotePawn(options[m.promotionChoice])
+
+    /* promote directly to a piece */
+    case "q":
+        m.promotePawn(options[0])
+    case "r":
+        m.promotePawn(options[1])
+    case "b":
+        m.promotePawn(options[2])
+    case "n":
+        m.promotePawn(options[3])
+    }
+
+    return m, nil
+}
+
 func (m model) View() string{
 
+    if m.choosingGame {
+        return m.gamePickerView()
+    }
+
     s := ""
 
     s += m.player2.name + ": [" +pieceArrToString(m.capturedP2) + "]\n"
 
+    boardStart := len(s)
+
     s += boardColor + "|---||---||---||---||---||---||---||---|\n"
 
-    var selectedPiece piece
+    var destinations []coordinate
 
     if m.selected.x != -1 {
-        selectedPiece = m.board[m.selected.y][m.selected.x]
-        logToFile("the selected piece has " + strconv.Itoa(len(selectedPiece.possibleMoves)) + "options")
+        destinations = m.getPossibleDestinations(m.selected)
+        logToFile("the piece on " + m.selected.String() + " has " + strconv.Itoa(len(destinations)) + " possible moves")
     }
 
     for i := 0; i < rowsAndColums; i++ {
@@ -244,10 +566,14 @@ func (m model) View() string{
         // draw cells
         for j := 0; j < rowsAndColums; j++ {
 
-            piece := m.board[i][j]
+            piece := m.pieceAt(coordinate{j, i})
 
             color := boardColor
 
+            if m.checkIfCheckedKing(coordinate{j, i}) {
+                color = checkColor
+            }
+
             if i == m.cursor.y && j == m.cursor.x {
                 color = highlightColor
             }
@@ -257,7 +583,7 @@ func (m model) View() string{
             }
 
             if m.selected.x != -1 {
-                for _, possibleMove := range selectedPiece.possibleMoves{
+                for _, possibleMove := range destinations{
                     if i == possibleMove.y && j == possibleMove.x {
                         color = possibleMoveColor
                         break
@@ -265,7 +591,7 @@ func (m model) View() string{
                 }
             }
 
-            s += color + "| " + pieceMarkupColor + piece.unicode + color + " |" + boardColor
+            s += color + "| " + pieceMarkupColor + getGlyph(piece) + color + " |" + boardColor
         }
 
         s += "\n"
@@ -274,8 +600,12 @@ func (m model) View() string{
         for j := 0; j < rowsAndColums; j++ {
             color := boardColor
 
+            if m.checkIfCheckedKing(coordinate{j, i}) || i < rowsAndColums - 1 && m.checkIfCheckedKing(coordinate{j, i + 1}) {
+                color = checkColor
+            }
+
             if m.selected.x != -1 {
-                for _, possibleMove := range selectedPiece.possibleMoves{
+                for _, possibleMove := range destinations{
                     if i == possibleMove.y && j == possibleMove.x || i == possibleMove.y - 1 && j == possibleMove.x {
                         color = possibleMoveColor
                         break
@@ -297,109 +627,193 @@ func (m model) View() string{
         s += "\n"
     }
 
+    // the move list is drawn to the right of the board
+    s = s[:boardStart] + m.addMoveList(s[boardStart:])
 
     s += m.player1.name + ": [" + pieceArrToString(m.capturedP1) + "]\n"
 
+    if m.result == "" {
+        if m.player1.checked {
+            s += "Check! " + m.player1.name + " is in check\n"
+        }
+        if m.player2.checked {
+            s += "Check! " + m.player2.name + " is in check\n"
+        }
+    }
+
+    if m.drawClaim != "" && m.result == "" {
+        s += m.drawClaim + ": a draw can be claimed, press d to claim\n"
+    }
+
+    if m.message != "" {
+        s += m.message + "\n"
+    }
+
+    if m.replaying {
+        s += m.replayHelp()
+    }
+
+    if comment := m.game.Current().Comment; comment != "" {
+        s += "{" + comment + "}\n"
+    }
+
+    if m.prompting {
+        s += m.promptView()
+    }
+
+    if m.editingComment {
+        s += m.commentEditorView()
+    }
+
+    if m.choosingNAG {
+        s += m.nagPickerView()
+    }
+
+    if m.promotion.x != -1 {
+        s += m.promotionView()
+    }
+
+    if m.result != "" {
+        s += m.gameOverView()
+    }
+
     return s
 }
 
-func (m *model) calculateMoves(){
-    //iterate over all pieces on the board and calculate the possible moves of each piece
-    logToFile("calculating all possible moves")
+// draws the piece picker shown below the board when a pawn is promoted
+func (m model) promotionView() string {
+    options := getPromotionOptions(m.pieceAt(m.selected).Color)
 
-    for i := 0; i < rowsAndColums; i++ {
-        for j := 0; j < rowsAndColums; j++ {
-            m.calculatePossibleMoves(coordinate{i, j})
+    s := "\n"
+
+    s += boardColor + "|---------------- promote pawn ------------------|\n"
+    s += "  the pawn on " + m.selected.String() + " promotes on " + m.promotion.String() + "\n"
+    s += "  "
+
+    for i, option := range options {
+        color := boardColor
+        if i == m.promotionChoice {
+            color = selectedColor
         }
+        s += color + "[ " + pieceMarkupColor + getGlyph(option) + color + " ]" + boardColor + " "
     }
-    logToFile("should have " + strconv.Itoa(len(m.board[6][0].possibleMoves)) + "options")
+
+    s += "\n"
+    s += "  h/l to choose and enter to promote, or q/r/b/n\n"
+    s += "|------------------------------------------------|\n" + Reset
+
+    return s
 }
 
-//creates an array of coordinates {-1, -1}, which will not be 
-func createEmptyCoordinateArray(length int) []coordinate{
-    emptyArray := make([]coordinate, length)
-    
-    for i := 0; i < length; i++ {
-        emptyArray[i] = coordinate{-1, -1}
+// draws the panel shown below the board when the game is over
+func (m model) gameOverView() string {
+    s := "\n"
+
+    s += boardColor + "|------------------ game over -------------------|\n"
+    s += "  " + m.resultReason + ", "
+
+    switch m.result {
+        case "1-0":
+            s += m.player1.name + " wins"
+        case "0-1":
+            s += m.player2.name + " wins"
+        default:
+            s += "the game is a draw"
     }
 
-    return emptyArray
-}
+    s += " (" + m.result + ")\n"
+    s += "  press n for a new game, u to take back the last move, s to save or q to quit\n"
+    s += "  f copies the position as FEN and p the game as PGN\n"
+    s += "|------------------------------------------------|\n" + Reset
 
-func (m model) checkIfEmpty(c coordinate) bool {
-    if m.board[c.y][c.x].unicode == empty.unicode {
-        return true
-    } 
-    return false
+    return s
 }
 
-func (m model) checkIfChecking(c coordinate) bool {
-    logToFile("king is checked")
-    piece := m.board[c.y][c.x]
-    if piece.unicode == kingBlack.unicode || piece.unicode == kingWhite.unicode {
-        return true
+// calculates the legal moves of every piece that can move
+func (m *model) calculateMoves(){
+    logToFile("calculating all possible moves")
+
+    // when freemoving both colors can move
+    if m.playerTurn == 0 {
+        m.possibleMoves = m.game.Position().FreeplayMoves()
+    } else {
+        m.possibleMoves = m.game.Position().LegalMoves()
     }
 
-    return false
+    logToFile("found " + strconv.Itoa(len(m.possibleMoves)) + " possible moves")
 }
 
-func (m model) checkIfSameColor(c1 coordinate, c2 coordinate) bool {
-    return m.board[c1.y][c1.x].pieceColor == m.board[c2.y][c2.x].pieceColor
+// the squares the piece on c can move to
+func (m model) getPossibleDestinations(c coordinate) []coordinate {
+    destinations := []coordinate{}
+
+    for _, mv := range m.possibleMoves {
+        if mv.From == squareOf(c) {
+            destinations = append(destinations, coordinateOf(mv.To))
+        }
+    }
+
+    return destinations
 }
 
-// checks if the move is a valid move and whether or not the move puts the king in check
-// a move that puts the king in check will be invalid
-func (m model) checkValidMove(piecePos, movePos coordinate) (valid bool, checking bool) {
+// the square in algebraic notation, for example e4, or - if c is not on the board
+func (c coordinate) String() string {
+    if c.x < 0 || c.x >= rowsAndColums || c.y < 0 || c.y >= rowsAndColums {
+        return "-"
+    }
+    return chess.SquareName(squareOf(c))
+}
 
-    valid = false
+// the square of the position at c
+func squareOf(c coordinate) int {
+    return chess.SquareAt(c.x, c.y)
+}
 
+func coordinateOf(sq int) coordinate {
+    x, y := chess.SquareXY(sq)
+    return coordinate{x, y}
+}
 
-    checking = m.checkIfChecking(movePos)
+// the piece on the square at c
+func (m model) pieceAt(c coordinate) chess.Piece {
+    return m.game.Position().PieceAt(squareOf(c))
+}
 
-    return false, false
+func (m model) checkIfEmpty(c coordinate) bool {
+    return m.pieceAt(c).Kind == chess.PieceKindNone
 }
 
 func (m  *model) selectSquare(){
 
-    logToFile("square selected")
+    logToFile("selected " + m.cursor.String())
 
-    cursorpiece := m.board[m.cursor.y][m.cursor.x]
-    var selectedPiece piece
-
-    if m.selected.x != -1 {
-        selectedPiece = m.board[m.selected.y][m.selected.x]
-    }
+    cursorpiece := m.pieceAt(m.cursor)
     
     //check player is deselecting
     if m.selected == m.cursor{
         m.selected = coordinate{-1, -1}
-        // TODO fix deselection to stop displaying the currently selected piece
-        //m.possibleMoves = []coordinate{}
 
     } else {
 
         //check if selection is a possible move
-        /* TODO fix moving of pieces by selecting a possible move */
         if m.selected.x != -1 {
-            for _, pos := range selectedPiece.possibleMoves{
-                logToFile(strconv.Itoa(pos.x) + strconv.Itoa(pos.y))
-                logToFile(strconv.Itoa(m.cursor.x) + strconv.Itoa(m.cursor.y))
+            for _, pos := range m.getPossibleDestinations(m.selected){
                 if pos == m.cursor{
-                    m.movePiece(pos, m.cursor)
-                    logToFile("moved piece")
+                    logToFile("moving " + m.selected.String() + " to " + pos.String())
+                    m.movePiece(m.getMove(m.selected, pos))
                     return
                 }
             }
         }
 
         //check if player is selecting the right piece
-        if (m.playerTurn == 1 && cursorpiece.pieceColor == pieceColorBlack) || 
-            (m.playerTurn == 2 && cursorpiece.pieceColor == pieceColorWhite) {
+        if (m.playerTurn == 1 && cursorpiece.Color == chess.PieceColorBlack) || 
+            (m.playerTurn == 2 && cursorpiece.Color == chess.PieceColorWhite) {
             return
         }
 
         //check if selection is an empty selectSquare
-        if m.board[m.cursor.y][m.cursor.x].unicode == empty.unicode {
+        if m.checkIfEmpty(m.cursor) {
             return
         }
 
@@ -407,501 +821,225 @@ func (m  *model) selectSquare(){
     }
 }
 
-func (m *model) movePiece(pos coordinate, piecePos coordinate){
-
-    piece := m.board[m.selected.y][m.selected.x]
-
-    //capturing
-    if m.board[pos.y][pos.x].unicode != empty.unicode {
-        if m.board[pos.y][pos.x].pieceColor == pieceColorWhite {
-            m.capturedP2 = append(m.capturedP2, m.board[pos.y][pos.x])
-        } else {
-            m.capturedP1 = append(m.capturedP1, m.board[pos.y][pos.x])
-        }
-    }
-
-    //move piece
-    m.board[pos.y][pos.x] = piece
-    m.board[m.selected.y][m.selected.x] = empty
-
-    //reset selected and possibleMoves
-    m.selected = coordinate{-1, -1}
-
-    // TODO fix to recalculate all possible moves
-    //m.possibleMoves = []coordinate{}
-
-    // switch turn
-    if m.playerTurn == 1 {
-        m.playerTurn = 2
-    } else if m.playerTurn == 2 {
-        m.playerTurn = 1
+// plays the move, or opens the promotion picker if the move promotes a pawn
+func (m *model) movePiece(mv chess.Move){
+    // a pawn reaching the last row is promoted, the move is made when the player has chosen a piece
+    if mv.Flags & chess.FlagPromotion != 0 {
+        m.promotion = coordinateOf(mv.To)
+        m.promotionChoice = 0
+        return
     }
 
-    //recalculate possible moves
-
-    m.calculateMoves()
+    m.makeMove(mv)
 }
 
-func (m *model) calculatePossibleMoves(c coordinate) (checking bool){
-    piece := m.board[c.y][c.x]
-    m.player1.checked = false
-    m.player2.checked = false
-
-    switch piece.unicode{
-        /* pawn movement */
-        case "♙", "♟︎":
-            logToFile("calculating pawn moves")
-            return m.calculatePossibleMovesPawn(c)
-
-        /* rook movement */
-        case "♖", "♜":
-            logToFile("calculating rook moves")
-            return m.calculatePossibleMovesRook(c)
-
-        /* bishop movement */
-        case "♗", "♝":
-            return m.calculatePossibleMovesBishop(c)
-
-        /* knight movement */
-        case "♞", "♘":
-            return m.calculatePossibleMovesKnight(c)
-
-        /* queen movement */
-        case "♕", "♛":
-            return m.calculatePossibleMovesQueen(c)
-
-        /* king movement */
-        case "♔", "♚":
-            return m.calculatePossibleMovesKing(c)
+// the possible move of the piece on from to the square to, promotions are to a queen
+func (m model) getMove(from coordinate, to coordinate) chess.Move {
+    for _, mv := range m.possibleMoves {
+        if mv.From == squareOf(from) && mv.To == squareOf(to) {
+            return mv
+        }
     }
-    return false
+    return chess.Move{}
 }
 
-func (m *model) calculatePossibleMovesPawn(pos coordinate) (checking bool) {
-    piece := &m.board[pos.y][pos.x]
+// promotes the selected pawn to the chosen piece
+func (m *model) promotePawn(p chess.Piece) {
+    logToFile("promoting pawn to " + getGlyph(p))
 
-    direction := 1
+    mv := m.getMove(m.selected, m.promotion)
+    mv.Promotion = p.Kind
+    m.promotion = coordinate{-1, -1}
 
-    if piece.pieceColor == pieceColorWhite {
-        direction = -1
-    }
-
-    piece.possibleMoves = []coordinate{
-    }
+    m.makeMove(mv)
+}
 
-    //check forward
-    if pos.y != 0 && pos.y != rowsAndColums -1 && m.board[pos.y + 1 * direction][pos.x].unicode == empty.unicode {
-        piece.possibleMoves = append(piece.possibleMoves, coordinate{pos.x, pos.y + 1 * direction})
+// plays one of the possible moves and ends the turn
+// a move that was taken back is played again, any other move starts a variation
+func (m *model) makeMove(mv chess.Move) {
+    // when freemoving the color of the moving piece decides whose turn it is
+    if m.playerTurn == 0 {
+        m.game.Position().SetTurn(mv.Piece.Color)
     }
 
-    //check if in initial position
-    if (piece.pieceColor == "black" && pos.y == 1 ||
-    piece.pieceColor == "white" && pos.y == 6 ) && 
-    m.board[pos.y + 2 * direction][pos.x].unicode == empty.unicode {
-        piece.possibleMoves = append(piece.possibleMoves, coordinate{pos.x, pos.y + 2 * direction})
+    //move piece
+    err, u := m.game.MakeMove(mv)
+    if err != nil {
+        logToFile(err.Error())
+        return
     }
+    m.logMove(m.game.Current().SAN)
 
-    //check for diagonals
-    if pos.x != 0 && pos.y != 0 && pos.y != rowsAndColums - 1 &&
-    m.board[pos.y + 1 * direction][pos.x - 1].unicode != empty.unicode && 
-    !m.checkIfSameColor(coordinate{pos.x - 1, pos.y + 1 * direction}, coordinate{pos.x, m.cursor.y}) {
-        if m.checkIfChecking(coordinate{pos.x - 1, pos.y + 1 * direction}) {
-            checking = true
-        }
-        piece.possibleMoves = append(piece.possibleMoves, coordinate{pos.x - 1, pos.y + 1 * direction})
-    }
-    if pos.x != 7 && pos.y != 0 && pos.y != rowsAndColums - 1 &&
-    m.board[pos.y + 1 * direction][pos.x + 1].unicode != empty.unicode &&
-    !m.checkIfSameColor(coordinate{pos.x + 1, pos.y + 1 * direction}, coordinate{pos.x, m.cursor.y}) {
-        if m.checkIfChecking(coordinate{pos.x - 1, pos.y + 1 * direction}) {
-            checking = true
+    //capturing
+    if u.Move.Captured.Kind != chess.PieceKindNone {
+        if u.Move.Captured.Color == chess.PieceColorWhite {
+            m.capturedP2 = append(m.capturedP2, u.Move.Captured)
+        } else {
+            m.capturedP1 = append(m.capturedP1, u.Move.Captured)
         }
-        piece.possibleMoves = append(piece.possibleMoves, coordinate{pos.x + 1, pos.y + 1 * direction})
     }
-    return checking
-}
 
-func (m *model) calculatePossibleMovesRook(pos coordinate) (checking bool) {
+    //reset selected
+    m.selected = coordinate{-1, -1}
 
-    piece := &m.board[pos.y][pos.x]
+    m.endTurn()
+}
 
-    piece.possibleMoves = []coordinate{
+// plays the move that continues the line again after it was taken back
+func (m *model) redoMove() {
+    next := m.game.Current().Children
+    if len(next) == 0 {
+        return
     }
 
-    // down
-    for i := 1; pos.y + i < rowsAndColums; i ++ {
-        moveCoor := coordinate{pos.x, pos.y + i}
-
-        if m.checkIfEmpty(moveCoor){
-            // if empty no need to check if checking
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
+    m.makeMove(next[0].Move)
+}
 
-    // up
-    for i := 1; pos.y - i >= 0; i ++ {
-        moveCoor := coordinate{pos.x, pos.y - i}
+// takes back the last move, this also works after the game is over
+// the move stays in the move tree and can be played again
+func (m *model) undoMove() {
+    san := m.game.Current().SAN
 
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+    if err, _ := m.game.UndoMove(); err != nil {
+        return
     }
+    logToFile("took back " + san)
 
-    // right
-    for i := 1; pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y}
+    m.followGame()
+}
 
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+// makes the variation the last move is in the main line, or moves it up if it is a variation inside a variation
+func (m *model) promoteVariation() {
+    if err := m.game.PromoteVariation(); err != nil {
+        m.message = err.Error()
+        return
     }
+    m.message = "promoted the variation"
+}
 
-    // left
-    for i := 1; pos.x - i >= 0; i++ {
-        
-        moveCoor := coordinate{pos.x - i, pos.y}
-
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+// removes the variation the last move is in, or the last move and the moves after it on the main line
+func (m *model) deleteVariation() {
+    if err := m.game.DeleteVariation(); err != nil {
+        m.message = err.Error()
+        return
     }
+    m.message = "deleted the moves"
 
-    return checking
+    m.followGame()
 }
 
-func (m *model) calculatePossibleMovesBishop(pos coordinate) (checking bool) {
-
-    piece := &m.board[pos.y][pos.x]
+// brings the captured pieces, turn and result up to date after the game moved to another node of the move tree
+func (m *model) followGame() {
+    m.capturedP1 = nil
+    m.capturedP2 = nil
 
-    piece.possibleMoves = []coordinate{}
-            
-    // right down
-    for i := 1; pos.y + i < rowsAndColums && pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y + i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
-
-    // right up
-    for i := 1; pos.y - i >= 0 && pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y - i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
+    for _, mv := range m.game.Moves() {
+        if mv.Captured.Kind == chess.PieceKindNone {
+            continue
         }
-    }
-
-    // left down
-    for i := 1; pos.y + i < rowsAndColums && pos.x - i >= 0; i++ {
-        moveCoor := coordinate{pos.x - i, pos.y + i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
+        if mv.Captured.Color == chess.PieceColorWhite {
+            m.capturedP2 = append(m.capturedP2, mv.Captured)
         } else {
-            break
+            m.capturedP1 = append(m.capturedP1, mv.Captured)
         }
     }
 
-    // left up
-    for i := 1; pos.y - i >= 0 && pos.x - i >= 0; i++ {
-        moveCoor := coordinate{pos.x - i, pos.y - i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
+    if m.playerTurn != 0 {
+        m.playerTurn = 1
+        if m.game.Position().Turn() == chess.PieceColorBlack {
+            m.playerTurn = 2
         }
     }
-    return checking
-}
-
-func (m *model) calculatePossibleMovesKnight(pos coordinate) (checking bool) {
-    piece := &m.board[pos.y][pos.x]
-    logToFile("calculating knights")
-
-    piece.possibleMoves = []coordinate{}
 
-    checkMoves := []coordinate{
-        {pos.x + 1, pos.y + 2},
-        {pos.x + 1, pos.y - 2},
-        {pos.x - 1, pos.y + 2},
-        {pos.x - 1, pos.y - 2},
-        {pos.x + 2, pos.y + 1},
-        {pos.x + 2, pos.y - 1},
-        {pos.x - 2, pos.y + 1},
-        {pos.x - 2, pos.y - 1},
-    }
+    m.selected = coordinate{-1, -1}
+    m.result = ""
+    m.resultReason = ""
 
-    for _, move := range checkMoves {
-        if move.x >= rowsAndColums || move.x < 0 || move.y >= rowsAndColums || move.y < 0 {
-            continue
-         }
-        if m.checkIfEmpty(move){
-            piece.possibleMoves = append(piece.possibleMoves, move)
-        } else if !m.checkIfSameColor(move, pos) {
-            if m.checkIfChecking(move) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, move)
-            continue
-        } else {
-            continue
-        }
-    }
-    return checking
+    m.calculateMoves()
+    m.checkForChecks()
+    m.checkForGameOver()
 }
 
-func (m *model) calculatePossibleMovesQueen(pos coordinate) (checking bool) {
-
-    piece := &m.board[pos.y][pos.x]
-
-    piece.possibleMoves = []coordinate{}
-    // queen logic is just copy paste rook and bishop
-
-    // down
-    for i := 1; pos.y + i < rowsAndColums; i ++ {
-        moveCoor := coordinate{pos.x, pos.y + i}
-
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+// switches the turn to the other player and checks if the game is over
+func (m *model) endTurn() {
+    // switch turn
+    if m.playerTurn == 1 {
+        m.playerTurn = 2
+    } else if m.playerTurn == 2 {
+        m.playerTurn = 1
     }
 
-    // up
-    for i := 1; pos.y - i >= 0; i ++ {
-        moveCoor := coordinate{pos.x, pos.y - i}
+    //recalculate possible moves
 
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
+    m.calculateMoves()
+    m.checkForChecks()
 
-    //left
-    for i := 1; pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y}
+    m.checkForGameOver()
+}
 
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+// the pieces a pawn of the given color can be promoted to
+func getPromotionOptions(color chess.PieceColor) []chess.Piece {
+    if color == chess.PieceColorWhite {
+        return []chess.Piece{queenWhite, rookWhite, bishopWhite, knightWhite}
     }
+    return []chess.Piece{queenBlack, rookBlack, bishopBlack, knightBlack}
+}
 
-    // left
-    for i := 1; pos.x - i >= 0; i++ {
-        
-        moveCoor := coordinate{pos.x - i, pos.y}
+// ends the game if the player whose turn it is has no possible moves left or the game is drawn
+func (m *model) checkForGameOver() {
+    m.drawClaim = ""
 
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
-    
-    // right down
-    for i := 1; pos.y + i < rowsAndColums && pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y + i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
-
-    // right up
-    for i := 1; pos.y - i >= 0 && pos.x + i < rowsAndColums; i++ {
-        moveCoor := coordinate{pos.x + i, pos.y - i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+    // there are no turns to run out of when freemoving
+    if m.playerTurn == 0 {
+        return
     }
 
-    // left down
-    for i := 1; pos.y + i < rowsAndColums && pos.x - i >= 0; i++ {
-        moveCoor := coordinate{pos.x - i, pos.y + i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
-    }
+    status := m.game.Status()
+    m.drawClaim = status.DrawClaim
 
-    // left up
-    for i := 1; pos.y - i >= 0 && pos.x - i >= 0; i++ {
-        moveCoor := coordinate{pos.x - i, pos.y - i}
-        if m.checkIfEmpty(moveCoor){
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-        } else if !m.checkIfSameColor(moveCoor, pos) {
-            if m.checkIfChecking(moveCoor) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, moveCoor)
-            break
-        } else {
-            break
-        }
+    if status.Result != "" {
+        m.result = status.Result
+        m.resultReason = status.Reason
+        logToFile("game over: " + m.resultReason + " " + m.result)
     }
+}
 
-    return checking
+// logs the move, written in standard algebraic notation
+// https://www.chessstrategyonline.com/content/tutorials/basic-chess-concepts-chess-notation
+// https://en.wikipedia.org/wiki/Portable_Game_Notation
+func (m *model) logMove(san string){
+    logToFile("move " + strconv.Itoa(len(m.game.Moves())) + ": " + san)
 }
 
-func (m *model) calculatePossibleMovesKing(pos coordinate) (checking bool) {
+// marks which players have their king in check, player 1 plays white
+func (m *model) checkForChecks() {
+    m.player1.checked = m.game.Position().InCheck(chess.PieceColorWhite)
+    m.player2.checked = m.game.Position().InCheck(chess.PieceColorBlack)
 
-    piece := &m.board[pos.y][pos.x]
-    piece.possibleMoves = []coordinate{} 
-    checkMoves := []coordinate{
-        {pos.x + 1, pos.y},
-        {pos.x - 1, pos.y},
-        {pos.x, pos.y + 1},
-        {pos.x, pos.y - 1},
-        {pos.x + 1, pos.y + 1},
-        {pos.x + 1, pos.y - 1},
-        {pos.x - 1, pos.y + 1},
-        {pos.x - 1, pos.y - 1},
+    if m.player1.checked || m.player2.checked {
+        logToFile("check")
     }
-    for _, move := range checkMoves {
-        if move.x >= rowsAndColums || move.x < 0 || move.y >= rowsAndColums || move.y < 0 {
-            continue
-         }
-        if m.checkIfEmpty(move){
-            piece.possibleMoves = append(piece.possibleMoves, move)
-        } else if !m.checkIfSameColor(move, pos) {
-            if m.checkIfChecking(move) {
-                checking = true
-            }
-            piece.possibleMoves = append(piece.possibleMoves, move)
-            continue
-        } else {
-            continue
-        }
-    }
-    return checking
 }
 
-// adds the move to the list of moves formatted as a chess move
-// https://www.chessstrategyonline.com/content/tutorials/basic-chess-concepts-chess-notation
-// https://en.wikipedia.org/wiki/Portable_Game_Notation
-func (m model) logMove(original_pos coordinate, new_pos coordinate){
-}
+// checks if there is a king in check on the square
+func (m model) checkIfCheckedKing(c coordinate) bool {
+    piece := m.pieceAt(c)
+    if piece.Kind != chess.PieceKindKing {
+        return false
+    }
 
-// check if the king is in check
-func (m model) checkForCheck() bool{
-    return false
+    if piece.Color == chess.PieceColorWhite {
+        return m.player1.checked
+    }
+    return m.player2.checked
 }
 
-//create a string of the unicode characters for an array of pieces
-func pieceArrToString(a []piece) string {
+//create a string of the glyphs for an array of pieces
+func pieceArrToString(a []chess.Piece) string {
     str := ""
 
     for i, piece := range a {
-        str += piece.unicode
+        str += getGlyph(piece)
         if i != len(a) - 1 {
             str += " "
         }
@@ -949,11 +1087,25 @@ func setColorConfig(config string) error {
             err, highlightColor = getColor(line_split[1])
         case "possible-color":
             err, possibleMoveColor = getColor(line_split[1])
+        case "check-color":
+            err, checkColor = getColor(line_split[1])
+        case "piece-set":
+            err, glyphs = getGlyphSet(line_split[1])
     }
 
     return err
 }
 
+func getGlyphSet(set string) (error, map[chess.PieceColor]map[chess.PieceKind]string) {
+    switch set{
+        case "unicode":
+            return nil, glyphsUnicode
+        case "letters":
+            return nil, glyphsLetters
+    }
+    return errors.New("unrecognized piece set"), glyphsUnicode
+}
+
 func getColor(c string) (error, string) {
     switch c{
         case "red", "Red":
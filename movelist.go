@@ -0,0 +1,173 @@
+package main
+
+import (
+    "strconv"
+    "strings"
+
+    "tui-chess/chess"
+)
+
+/* size of the move list drawn beside the board */
+const boardWidth = 5 * rowsAndColums
+const moveListGap = 3
+const moveListMinWidth = 12
+const moveListMaxWidth = 28
+
+// draws the move list to the right of the lines of the board
+// the list is as high as the board and scrolls to keep the current move visible
+func (m model) addMoveList(board string) string {
+    lines := strings.Split(strings.TrimSuffix(board, "\n"), "\n")
+
+    width := moveListMaxWidth
+    if m.width != 0 && m.width - boardWidth - moveListGap < width {
+        width = m.width - boardWidth - moveListGap
+    }
+
+    // there is no room for the list in narrow terminals
+    if width < moveListMinWidth {
+        return board
+    }
+
+    list := m.moveListView(len(lines), width)
+
+    s := ""
+    for i, line := range lines {
+        s += line + Reset + strings.Repeat(" ", moveListGap) + list[i] + "\n"
+    }
+
+    return s
+}
+
+// a line of the move list, a move number followed by up to two moves, or a comment
+type moveRow struct {
+    // how deep the row is nested in variations, 0 for the main line
+    depth int
+    // the half moves from the first move of white to the first move of the row
+    ply int
+    moves []*chess.MoveNode
+    // the comment on the move before the row, rows with a comment have no moves
+    comment string
+}
+
+// the rows of the move list, each move is followed by a row for its comment
+// and rows for its variations which are indented below it
+// newRow is false when the first move continues the last row
+func (m model) moveRows(rows []moveRow, n *chess.MoveNode, ply int, depth int, newRow bool) []moveRow {
+    for len(n.Children) != 0 {
+        main := n.Children[0]
+
+        if main.CommentBefore != "" {
+            rows = appendCommentRow(rows, main.CommentBefore, depth, ply)
+            newRow = true
+        }
+        if newRow || ply % 2 == 0 {
+            rows = append(rows, moveRow{depth: depth, ply: ply})
+        }
+        rows[len(rows) - 1].moves = append(rows[len(rows) - 1].moves, main)
+        rows = appendCommentRow(rows, main.Comment, depth, ply)
+
+        for _, variation := range n.Children[1:] {
+            rows = appendCommentRow(rows, variation.CommentBefore, depth + 1, ply)
+            rows = append(rows, moveRow{depth: depth + 1, ply: ply, moves: []*chess.MoveNode{variation}})
+            rows = appendCommentRow(rows, variation.Comment, depth + 1, ply)
+            rows = m.moveRows(rows, variation, ply + 1, depth + 1, variation.Comment != "")
+        }
+
+        // the line continues on a new row after a comment or variations
+        newRow = main.Comment != "" || len(n.Children) > 1
+        n = main
+        ply ++
+    }
+
+    return rows
+}
+
+// appends a row for the comment if there is one, ply is the ply of the move the comment is on
+func appendCommentRow(rows []moveRow, comment string, depth int, ply int) []moveRow {
+    if comment == "" {
+        return rows
+    }
+    return append(rows, moveRow{depth: depth, ply: ply, comment: comment})
+}
+
+// the lines of the move list, each line is a move number followed by the moves of white and black
+func (m model) moveListView(height int, width int) []string {
+    start := m.game.StartPosition()
+    firstPly := (start.FullmoveNumber() - 1) * 2 + int(start.Turn())
+
+    rows := appendCommentRow(nil, m.game.Root().Comment, 0, firstPly)
+    rows = m.moveRows(rows, m.game.Root(), firstPly, 0, true)
+
+    // scroll so the row of the current move is the last one shown
+    visible := height - 1
+    first := 0
+    current := m.game.Current()
+    for i, r := range rows {
+        for _, node := range r.moves {
+            if node == current && i >= visible {
+                first = i - visible + 1
+            }
+        }
+    }
+
+    header := "moves"
+    if len(rows) > visible {
+        last := first + visible
+        if last > len(rows) {
+            last = len(rows)
+        }
+        header += " " + strconv.Itoa(rows[first].ply / 2 + 1) + "-" + strconv.Itoa(rows[last - 1].ply / 2 + 1) +
+            " of " + strconv.Itoa(rows[len(rows) - 1].ply / 2 + 1)
+    }
+
+    lines := []string{boardColor + truncate(header, width) + Reset}
+
+    for i := first; i < len(rows) && len(lines) < height; i++ {
+        r := rows[i]
+
+        if r.comment != "" {
+            lines = append(lines, boardColor + truncate(strings.Repeat(" ", 2 * r.depth) + "{" + r.comment + "}", width) + Reset)
+            continue
+        }
+
+        number := strings.Repeat(" ", 2 * r.depth) + strconv.Itoa(r.ply / 2 + 1) + "."
+        if r.ply % 2 == 1 {
+            number += ".."
+        }
+
+        line := boardColor + number
+        length := len(number)
+
+        for _, node := range r.moves {
+            san := node.Annotated()
+            if length + 1 + len(san) > width {
+                break
+            }
+
+            color := pieceMarkupColor
+            if node == current {
+                color = highlightColor
+            }
+
+            line += " " + color + san
+            length += 1 + len(san)
+        }
+
+        lines = append(lines, line + Reset)
+    }
+
+    for len(lines) < height {
+        lines = append(lines, "")
+    }
+
+    return lines
+}
+
+// cuts the text to the given width
+func truncate(s string, width int) string {
+    runes := []rune(s)
+    if len(runes) > width {
+        return string(runes[:width])
+    }
+    return s
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "strconv"
+    "time"
+
+    "tui-chess/chess"
+)
+
+// runs perft from the command line, the standard perft positions are checked by the tests of the chess package
+// tui-chess perft <depth> [board] counts the leaf nodes from a board, the default board if none is given
+// tui-chess divide <depth> [board] also prints the leaf nodes below each move
+// with --fen both start from a position in FEN instead of a board
+func runPerft(command string, args []string) error {
+    usage := "usage: tui-chess " + command + " [--fen <fen>] <depth> [board]"
+
+    options := flag.NewFlagSet(command, flag.ContinueOnError)
+    fen := options.String("fen", "", "start from the position in `FEN`")
+    // the usage is returned as the error instead
+    options.Usage = func() {}
+    if err := options.Parse(args); err != nil {
+        return errors.New(usage)
+    }
+    args = options.Args()
+
+    if len(args) == 0 || len(args) > 2 || *fen != "" && len(args) == 2 {
+        return errors.New(usage)
+    }
+
+    depth, err := strconv.Atoi(args[0])
+    if err != nil || depth < 1 {
+        return errors.New("depth must be a number above 0")
+    }
+
+    var p chess.Position
+    if *fen != "" {
+        err, p = chess.ParseFEN(*fen)
+        if err == nil {
+            err = p.Validate()
+        }
+        if err != nil {
+            return errors.New("invalid FEN: " + err.Error())
+        }
+    } else {
+        name := "default"
+        if len(args) == 2 {
+            name = args[1]
+        }
+
+        err, p = getBoard(name)
+        if err != nil {
+            return err
+        }
+    }
+
+    start := time.Now()
+    nodes := 0
+
+    if command == "divide" {
+        for _, result := range chess.Divide(&p, depth) {
+            fmt.Printf("%v: %d\n", result.Move, result.Nodes)
+            nodes += result.Nodes
+        }
+        fmt.Println()
+    } else {
+        nodes = chess.Perft(&p, depth)
+    }
+
+    fmt.Printf("nodes: %d\ntime: %v\n", nodes, time.Since(start))
+
+    return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "tui-chess/chess"
+)
+
+// the game in PGN with its variations, with player 1 as white and player 2 as black
+// a game opened from a PGN file keeps the tags it had in the file
+func (m model) pgn() string {
+    tags := []chess.Tag{
+        {Name: "Event", Value: "Casual game"},
+        {Name: "Site", Value: "tui-chess"},
+        {Name: "Date", Value: m.started.Format("2006.01.02")},
+        {Name: "Round", Value: "-"},
+        {Name: "White", Value: m.player1.name},
+        {Name: "Black", Value: m.player2.name},
+    }
+    if m.pgnTags != nil {
+        tags = append([]chess.Tag{}, m.pgnTags...)
+    }
+
+    // a claimed draw at the end of the main line is not part of the game status,
+    // other results are found by the game itself
+    if m.result != "" && m.game.OnMainLine() && len(m.game.Current().Children) == 0 {
+        tags = append(tags, chess.Tag{Name: "Result", Value: m.result})
+    }
+
+    return m.game.PGN(tags)
+}
+
+// writes the game to the PGN file
+func (m *model) savePGN() {
+    if m.pgnOpened != "" && sameFile(m.pgnOpened, m.pgnFile) {
+        m.message = "could not save the game: " + m.pgnFile + " is the PGN file the games were opened from"
+        logToFile(m.message)
+        return
+    }
+
+    err := os.WriteFile(m.pgnFile, []byte(m.pgn()), 0644)
+    if err != nil {
+        m.message = "could not save the game: " + err.Error()
+        logToFile(m.message)
+        return
+    }
+
+    m.message = "saved the game to " + m.pgnFile
+    logToFile(m.message)
+}
+
+// saves the game of the model bubbletea returns when the program quits
+// nothing is saved if no move was played and no game was opened, for example when quitting the game picker
+func savePGNOnQuit(final tea.Model) {
+    m, ok := final.(model)
+    if !ok || !m.hasGame() {
+        return
+    }
+
+    m.savePGN()
+    fmt.Println(m.message)
+}
+
+// checks if a game was played or opened from a PGN file, the game picker has no game open
+func (m model) hasGame() bool {
+    return !m.choosingGame && (len(m.game.Root().Children) != 0 || m.pgnTags != nil)
+}
+
+// checks if the two paths are the same file, a file that does not exist yet is never the same file
+func sameFile(a string, b string) bool {
+    infoA, err := os.Stat(a)
+    if err != nil {
+        return false
+    }
+    infoB, err := os.Stat(b)
+    if err != nil {
+        return false
+    }
+    return os.SameFile(infoA, infoB)
+}
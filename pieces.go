@@ -1,69 +1,97 @@
 package main
 
+import "tui-chess/chess"
+
 /* pieces */
 
-var pawnBlack = piece{
-    unicode : "♙",
-    pieceColor: pieceColorBlack,
+var rookBlack = chess.Piece{
+    Kind: chess.PieceKindRook,
+    Color: chess.PieceColorBlack,
 }
 
-var rookBlack = piece{
-    unicode: "♖",
-    pieceColor: pieceColorBlack,
+var knightBlack = chess.Piece{
+    Kind: chess.PieceKindKnight,
+    Color: chess.PieceColorBlack,
 }
 
-var knightBlack = piece{
-    unicode: "♘",
-    pieceColor: pieceColorBlack,
+var bishopBlack = chess.Piece{
+    Kind: chess.PieceKindBishop,
+    Color: chess.PieceColorBlack,
 }
 
-var bishopBlack = piece{
-    unicode: "♗",
-    pieceColor: pieceColorBlack,
+var queenBlack = chess.Piece{
+    Kind: chess.PieceKindQueen,
+    Color: chess.PieceColorBlack,
 }
 
-var queenBlack = piece{
-    unicode: "♕",
-    pieceColor: pieceColorBlack,
+var rookWhite = chess.Piece{
+    Kind: chess.PieceKindRook,
+    Color: chess.PieceColorWhite,
 }
 
-var kingBlack = piece{
-    unicode: "♔",
-    pieceColor: pieceColorBlack,
+var knightWhite = chess.Piece{
+    Kind: chess.PieceKindKnight,
+    Color: chess.PieceColorWhite,
 }
 
-var pawnWhite = piece{
-    unicode : "♟︎",
-    pieceColor: pieceColorWhite,
+var bishopWhite = chess.Piece{
+    Kind: chess.PieceKindBishop,
+    Color: chess.PieceColorWhite,
 }
 
-var rookWhite = piece{
-    unicode: "♜",
-    pieceColor: pieceColorWhite,
+var queenWhite = chess.Piece{
+    Kind: chess.PieceKindQueen,
+    Color: chess.PieceColorWhite,
 }
 
-var knightWhite = piece{
-    unicode: "♞",
-    pieceColor: pieceColorWhite,
-}
+/* glyphs, these are only used when drawing pieces */
 
-var bishopWhite = piece{
-    unicode: "♝",
-    pieceColor: pieceColorWhite,
+var glyphsUnicode = map[chess.PieceColor]map[chess.PieceKind]string{
+    chess.PieceColorBlack: {
+        chess.PieceKindPawn: "♙",
+        chess.PieceKindRook: "♖",
+        chess.PieceKindKnight: "♘",
+        chess.PieceKindBishop: "♗",
+        chess.PieceKindQueen: "♕",
+        chess.PieceKindKing: "♔",
+    },
+    chess.PieceColorWhite: {
+        chess.PieceKindPawn: "♟︎",
+        chess.PieceKindRook: "♜",
+        chess.PieceKindKnight: "♞",
+        chess.PieceKindBishop: "♝",
+        chess.PieceKindQueen: "♛",
+        chess.PieceKindKing: "♚",
+    },
 }
 
-var queenWhite = piece{
-    unicode: "♛",
-    pieceColor: pieceColorWhite,
+var glyphsLetters = map[chess.PieceColor]map[chess.PieceKind]string{
+    chess.PieceColorBlack: {
+        chess.PieceKindPawn: "p",
+        chess.PieceKindRook: "r",
+        chess.PieceKindKnight: "n",
+        chess.PieceKindBishop: "b",
+        chess.PieceKindQueen: "q",
+        chess.PieceKindKing: "k",
+    },
+    chess.PieceColorWhite: {
+        chess.PieceKindPawn: "P",
+        chess.PieceKindRook: "R",
+        chess.PieceKindKnight: "N",
+        chess.PieceKindBishop: "B",
+        chess.PieceKindQueen: "Q",
+        chess.PieceKindKing: "K",
+    },
 }
 
-var kingWhite = piece{
-    unicode: "♚",
-    pieceColor: pieceColorWhite,
-}
+// the glyph set used for drawing, can be changed with piece-set in the config file
+var glyphs = glyphsUnicode
 
-var empty = piece{
-    unicode: " ",
-    pieceColor: "none",
+// returns the glyph used to draw the piece, empty squares are drawn as a space
+func getGlyph(p chess.Piece) string {
+    glyph, ok := glyphs[p.Color][p.Kind]
+    if !ok {
+        return " "
+    }
+    return glyph
 }
-
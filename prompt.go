@@ -0,0 +1,159 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "tui-chess/chess"
+)
+
+// the most suggestions shown below the move prompt
+const maxSuggestions = 10
+
+// handles key presses while a move is typed in the move prompt
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    switch msg.Type {
+
+    /* quit program, letters are part of the move */
+    case tea.KeyCtrlC, tea.KeyCtrlD:
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* close the prompt */
+    case tea.KeyEsc:
+        m.closePrompt()
+
+    /* play the typed move */
+    case tea.KeyEnter:
+        m.playTypedMove()
+
+    /* complete the typed move */
+    case tea.KeyTab:
+        m.completeInput()
+
+    /* delete the last letter */
+    case tea.KeyBackspace:
+        m.input = dropLastRune(m.input)
+        m.inputError = ""
+
+    /* type */
+    case tea.KeyRunes:
+        // typing / again closes the prompt
+        if string(msg.Runes) == "/" {
+            m.closePrompt()
+            break
+        }
+        m.input += string(msg.Runes)
+        m.inputError = ""
+    }
+
+    return m, nil
+}
+
+// removes the last character of the text, which can be more than one byte
+func dropLastRune(s string) string {
+    _, size := utf8.DecodeLastRuneInString(s)
+    return s[:len(s) - size]
+}
+
+func (m *model) closePrompt() {
+    m.prompting = false
+    m.input = ""
+    m.inputError = ""
+}
+
+// plays the move typed in the prompt, or shows why it can not be played
+func (m *model) playTypedMove() {
+    err, mv := m.parseMove(m.input)
+    if err != nil {
+        m.inputError = err.Error()
+        logToFile("typed move " + m.input + ": " + m.inputError)
+        return
+    }
+
+    m.closePrompt()
+    m.makeMove(mv)
+}
+
+// reads a typed move in SAN or long algebraic notation
+// when freemoving the moves of both colors are tried, those of the player whose turn it is first
+func (m model) parseMove(s string) (error, chess.Move) {
+    p := *m.game.Position()
+
+    err, mv := p.ParseMove(s)
+    if err == nil || m.playerTurn != 0 {
+        return err, mv
+    }
+
+    p.SetTurn(p.Turn().Opponent())
+    if otherErr, otherMv := p.ParseMove(s); otherErr == nil {
+        return nil, otherMv
+    }
+
+    return err, mv
+}
+
+// the possible moves in SAN that start with the typed text, in SAN or long algebraic notation
+func (m model) suggestions() []string {
+    found := []string{}
+
+    for _, mv := range m.possibleMoves {
+        san := m.moveSAN(mv)
+        if strings.HasPrefix(san, m.input) || strings.HasPrefix(mv.String(), m.input) {
+            found = append(found, san)
+        }
+    }
+
+    return found
+}
+
+// the move in SAN, the moves of both colors can be written when freemoving
+func (m model) moveSAN(mv chess.Move) string {
+    p := *m.game.Position()
+    p.SetTurn(mv.Piece.Color)
+    return p.SAN(mv)
+}
+
+// completes the typed text to the one suggested move, or as far as all suggested moves agree
+func (m *model) completeInput() {
+    suggestions := m.suggestions()
+    if len(suggestions) == 0 {
+        return
+    }
+
+    // moves typed in long algebraic notation are completed in SAN
+    common := suggestions[0]
+    for _, s := range suggestions[1:] {
+        for !strings.HasPrefix(s, common) {
+            common = common[:len(common) - 1]
+        }
+    }
+
+    if len(common) > len(m.input) || len(suggestions) == 1 {
+        m.input = common
+    }
+}
+
+// draws the move prompt with the suggested moves or the reason the move can not be played
+func (m model) promptView() string {
+    s := "move: " + m.input + "_\n"
+
+    if m.inputError != "" {
+        return s + checkColor + m.inputError + Reset + "\n"
+    }
+
+    suggestions := m.suggestions()
+    switch {
+        case len(suggestions) == 0:
+            s += "no possible move starts with " + m.input + "\n"
+        case len(suggestions) > maxSuggestions:
+            s += strings.Join(suggestions[:maxSuggestions], " ") + " ...\n"
+        default:
+            s += strings.Join(suggestions, " ") + "\n"
+    }
+
+    return s + "enter to move, tab to complete and esc to cancel\n"
+}
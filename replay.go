@@ -0,0 +1,147 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "tui-chess/chess"
+)
+
+// opens the games read from a PGN file, a file with more than one game starts with the game picker
+func (m *model) openPGN(games []chess.PGNGame) {
+    m.pgnGames = games
+
+    if len(games) == 1 {
+        m.loadPGNGame(0)
+        return
+    }
+
+    m.choosingGame = true
+}
+
+// shows the start of a game from the PGN file, its moves are stepped through with the arrow keys
+func (m *model) loadPGNGame(i int) {
+    pgnGame := m.pgnGames[i]
+
+    // the game is copied so moves played on the board do not change the game in the picker
+    m.game = pgnGame.Game.Clone()
+    m.game.GoTo(m.game.Root())
+
+    // a game without a Result tag keeps the result written after its moves
+    m.pgnTags = pgnGame.Tags
+    if pgnGame.Tag("Result") == "" && pgnGame.Result != "" {
+        m.pgnTags = append(append([]chess.Tag{}, pgnGame.Tags...), chess.Tag{Name: "Result", Value: pgnGame.Result})
+    }
+    m.player1.name = playerName(pgnGame.Tag("White"), "player 1")
+    m.player2.name = playerName(pgnGame.Tag("Black"), "player 2")
+
+    m.playerTurn = 1
+    m.promotion = coordinate{-1, -1}
+    m.replaying = true
+    m.choosingGame = false
+    m.gameChoice = i
+
+    m.followGame()
+
+    logToFile("opened game " + strconv.Itoa(i + 1) + " of " + strconv.Itoa(len(m.pgnGames)) + " with " +
+        strconv.Itoa(len(m.game.MainLine())) + " moves")
+}
+
+// the name of a player from a PGN tag, unknown players keep the default name
+func playerName(tag string, name string) string {
+    if tag == "" || tag == "?" {
+        return name
+    }
+    return tag
+}
+
+// the result of a game from a PGN file, games without a Result tag have the result written after their moves
+func pgnResult(g chess.PGNGame) string {
+    if result := g.Tag("Result"); result != "" {
+        return result
+    }
+    return g.Result
+}
+
+// handles key presses in the game picker
+func (m model) updateGamePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    switch msg.String() {
+
+    /* quit program */
+    case "ctrl+c", "ctrl+d", "q":
+        fmt.Println("Thanks for playing!")
+        return m, tea.Quit
+
+    /* move choice down */
+    case "j", "down":
+        if m.gameChoice < len(m.pgnGames) - 1 {
+            m.gameChoice ++
+        }
+
+    /* move choice up */
+    case "k", "up":
+        if m.gameChoice != 0 {
+            m.gameChoice --
+        }
+
+    /* open the chosen game */
+    case "enter", " ":
+        m.loadPGNGame(m.gameChoice)
+    }
+
+    return m, nil
+}
+
+// draws the list of games in the PGN file
+func (m model) gamePickerView() string {
+    s := boardColor + "|------------------ choose a game -------------------|\n" + Reset
+
+    // only the games around the chosen one are shown on small terminals
+    first, last := 0, len(m.pgnGames)
+    if m.height > 4 && last > m.height - 4 {
+        first = m.gameChoice - (m.height - 4) / 2
+        if first < 0 {
+            first = 0
+        }
+        last = first + m.height - 4
+        if last > len(m.pgnGames) {
+            last = len(m.pgnGames)
+            first = last - (m.height - 4)
+        }
+    }
+
+    for i := first; i < last; i++ {
+        g := m.pgnGames[i]
+
+        color := ""
+        if i == m.gameChoice {
+            color = highlightColor
+        }
+
+        s += color + fmt.Sprintf("%3d. %s - %s, %s, %s", i + 1, playerName(g.Tag("White"), "?"),
+            playerName(g.Tag("Black"), "?"), g.Tag("Date"), pgnResult(g)) + Reset + "\n"
+    }
+
+    s += "\nj/k to choose, enter to open the game and q to quit\n"
+
+    return s
+}
+
+// the keys for stepping through the game, shown below the board
+func (m model) replayHelp() string {
+    // the moves of the line shown on the board, up to its end
+    played := len(m.game.Moves())
+    total := played
+    for n := m.game.Current(); len(n.Children) != 0; n = n.Children[0] {
+        total ++
+    }
+
+    s := "move " + strconv.Itoa(played) + " of " + strconv.Itoa(total) +
+        ", left/right to step through the game, home/end to jump to the start or end"
+    if len(m.pgnGames) > 1 {
+        s += ", g to choose another game"
+    }
+    return s + "\n"
+}